@@ -0,0 +1,310 @@
+// Go client of the HPSDRProgrammer_web REST API
+// GPL2
+//
+package hpsdrclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Path of the API on the server, see /api/v1/openapi.json
+const Apipath string = "/api/v1"
+
+// Client of one HPSDRProgrammer_web server.
+type Client struct {
+	Base  string       // such as http://localhost:8228
+	Token string       // the -token of the server, when it has one
+	HTTP  *http.Client // http.DefaultClient when nil
+}
+
+// Error body of a failed call, see the Error schema.
+type Error struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"` // badrequest, unauthorized, forbidden, notfound, timeout, conflict, file, method, unavailable, failed
+	Message string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("hpsdr api %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// Is matches the newopenhpsdr errors the code stands for, so callers can
+// test for errors.Is(err, newopenhpsdr.ErrTimeout) as with the library.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case newopenhpsdr.ErrTimeout:
+		return e.Code == "timeout"
+	case newopenhpsdr.ErrFileInvalid:
+		return e.Code == "file"
+	}
+	return false
+}
+
+// Body of Discover
+type Discoveryrequest struct {
+	Index   int    `json:"index"`             // interface to broadcast on
+	Targets string `json:"targets,omitempty"` // comma separated board addresses to unicast to instead
+}
+
+// One discovery run
+type Discovery struct {
+	ID      string                    `json:"id"`
+	Index   int                       `json:"index"`
+	Intface string                    `json:"intface"`
+	Targets string                    `json:"targets,omitempty"`
+	Time    time.Time                 `json:"time"`
+	Boards  []newopenhpsdr.Hpsdrboard `json:"boards"`
+}
+
+// Body of Setip, Address "dhcp" returns the board to DHCP.
+type Setiprequest struct {
+	Index   int    `json:"index"`
+	Targets string `json:"targets,omitempty"`
+	Address string `json:"address"`
+}
+
+// An image of the firmware library, the reply of Upload
+type Firmware struct {
+	Name     string                 `json:"name"`
+	Path     string                 `json:"path"`
+	Sha256   string                 `json:"sha256"` // use as Jobrequest.Rbf
+	Rbf      newopenhpsdr.Rbfinfo   `json:"rbf"`
+	Board    string                 `json:"board,omitempty"`
+	Firmware string                 `json:"firmware,omitempty"`
+	Uploaded time.Time              `json:"uploaded"`
+	Manifest *newopenhpsdr.Manifest `json:"manifest,omitempty"`
+}
+
+// Body of Program
+type Jobrequest struct {
+	Index   int    `json:"index"`
+	Targets string `json:"targets,omitempty"`
+	Mac     string `json:"mac"`
+	Rbf     string `json:"rbf"`
+	Verify  bool   `json:"verify"`
+	Force   bool   `json:"force"`
+}
+
+// Progress of a job
+type Progress struct {
+	State   string  `json:"state"` // queued, checking, erasing, erased, programming, programmed, verifying, done, failed
+	Erase   string  `json:"erase"`
+	Program string  `json:"program"`
+	Verify  string  `json:"verify"`
+	Percent float64 `json:"percent"`
+	Block   uint32  `json:"block"`
+	Blocks  uint32  `json:"blocks"`
+	Retries int     `json:"retries"`
+	Rate    float64 `json:"rate"` // bytes per second
+	Eta     float64 `json:"eta"`  // seconds left
+	Seconds float64 `json:"seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// A programming job
+type Job struct {
+	ID       string                  `json:"id"`
+	Index    int                     `json:"index"`
+	Intface  string                  `json:"intface"`
+	Targets  string                  `json:"targets,omitempty"`
+	Board    newopenhpsdr.Hpsdrboard `json:"board"`
+	Rbffile  string                  `json:"rbffile"`
+	Verify   bool                    `json:"verify"`
+	Force    bool                    `json:"force"`
+	State    string                  `json:"state"`
+	Batch    string                  `json:"batch,omitempty"`
+	Progress Progress                `json:"progress"`
+	Created  time.Time               `json:"created"`
+	Modified time.Time               `json:"modified"`
+}
+
+// Report whether the job has ended, done or failed.
+func (j Job) Finished() bool {
+	return (j.State == "done") || (j.State == "failed")
+}
+
+// Client of the server at base, such as http://localhost:8228.
+func New(base string) *Client {
+	return &Client{Base: strings.TrimRight(base, "/")}
+}
+
+// Send a request and decode the JSON reply into v, a reply that is not
+// 2xx is returned as an *Error.
+func (c *Client) do(ctx context.Context, method string, path string, ctype string, body io.Reader, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.Base+Apipath+path, body)
+	if err != nil {
+		return err
+	}
+	if ctype != "" {
+		req.Header.Set("Content-Type", ctype)
+	}
+	req.Header.Set("Accept", "application/json")
+	// a header a cross site form cannot send, the server wants it on
+	// calls that change something when there is no token
+	req.Header.Set("X-Requested-With", "hpsdrclient")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if (res.StatusCode < 200) || (res.StatusCode > 299) {
+		e := &Error{Status: res.StatusCode}
+		if json.NewDecoder(res.Body).Decode(e) != nil || e.Code == "" {
+			e.Code = "failed"
+			e.Message = res.Status
+		}
+		return e
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// Send v as the JSON body.
+func (c *Client) dojson(ctx context.Context, method string, path string, in interface{}, v interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, path, "application/json", bytes.NewReader(b), v)
+}
+
+// Network interfaces of the server.
+func (c *Client) Interfaces(ctx context.Context) (intf []newopenhpsdr.Intface, er error) {
+	er = c.do(ctx, http.MethodGet, "/interfaces", "", nil, &intf)
+	return intf, er
+}
+
+// Discover the boards on an interface of the server, or at addresses.
+func (c *Client) Discover(ctx context.Context, req Discoveryrequest) (d Discovery, er error) {
+	er = c.dojson(ctx, http.MethodPost, "/discoveries", req, &d)
+	return d, er
+}
+
+// Recent discoveries, oldest first.
+func (c *Client) Discoveries(ctx context.Context) (dl []Discovery, er error) {
+	er = c.do(ctx, http.MethodGet, "/discoveries", "", nil, &dl)
+	return dl, er
+}
+
+// One discovery by ID.
+func (c *Client) Discovery(ctx context.Context, id string) (d Discovery, er error) {
+	er = c.do(ctx, http.MethodGet, "/discoveries/"+url.PathEscape(id), "", nil, &d)
+	return d, er
+}
+
+// Set the IP address of the board with the MAC address.
+func (c *Client) Setip(ctx context.Context, mac string, req Setiprequest) (msg newopenhpsdr.SetIPmessage, er error) {
+	er = c.dojson(ctx, http.MethodPut, "/boards/"+url.PathEscape(mac)+"/ip", req, &msg)
+	return msg, er
+}
+
+// Upload an RBF file read from r to the firmware library, name is the
+// file name kept on the server.  An image already there is returned as it
+// is.
+func (c *Client) Upload(ctx context.Context, name string, r io.Reader) (fw Firmware, er error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("file", filepath.Base(name))
+	if err != nil {
+		return fw, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return fw, err
+	}
+	if err := mw.Close(); err != nil {
+		return fw, err
+	}
+	er = c.do(ctx, http.MethodPost, "/firmware", mw.FormDataContentType(), &buf, &fw)
+	return fw, er
+}
+
+// Upload a local RBF file.
+func (c *Client) Uploadfile(ctx context.Context, filename string) (Firmware, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Firmware{}, err
+	}
+	defer f.Close()
+	return c.Upload(ctx, filename, f)
+}
+
+// Images of the firmware library, newest first.
+func (c *Client) Library(ctx context.Context) (fl []Firmware, er error) {
+	er = c.do(ctx, http.MethodGet, "/firmware", "", nil, &fl)
+	return fl, er
+}
+
+// One image of the firmware library by its SHA-256.
+func (c *Client) Image(ctx context.Context, sha256 string) (fw Firmware, er error) {
+	er = c.do(ctx, http.MethodGet, "/firmware/"+url.PathEscape(sha256), "", nil, &fw)
+	return fw, er
+}
+
+// Remove an image from the firmware library, refused with a conflict
+// while a job is programming it.
+func (c *Client) Deleteimage(ctx context.Context, sha256 string) error {
+	return c.do(ctx, http.MethodDelete, "/firmware/"+url.PathEscape(sha256), "", nil, nil)
+}
+
+// Start erasing and programming a board, the job runs on the server, see
+// Job and Wait.
+func (c *Client) Program(ctx context.Context, req Jobrequest) (j Job, er error) {
+	er = c.dojson(ctx, http.MethodPost, "/jobs", req, &j)
+	return j, er
+}
+
+// Every job of the server, oldest first.
+func (c *Client) Jobs(ctx context.Context) (jl []Job, er error) {
+	er = c.do(ctx, http.MethodGet, "/jobs", "", nil, &jl)
+	return jl, er
+}
+
+// One job by ID.
+func (c *Client) Job(ctx context.Context, id string) (j Job, er error) {
+	er = c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), "", nil, &j)
+	return j, er
+}
+
+// Poll the job every poll until it is finished or ctx ends.
+func (c *Client) Wait(ctx context.Context, id string, poll time.Duration) (Job, error) {
+	if poll <= 0 {
+		poll = time.Second
+	}
+	tick := time.NewTicker(poll)
+	defer tick.Stop()
+	for {
+		j, err := c.Job(ctx, id)
+		if err != nil || j.Finished() {
+			return j, err
+		}
+		select {
+		case <-ctx.Done():
+			return j, ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
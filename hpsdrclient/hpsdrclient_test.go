@@ -0,0 +1,262 @@
+// Tests of the REST API client against a fake server
+// GPL2
+//
+package hpsdrclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+const testmac string = "00:1c:c0:a2:13:01"
+
+// Server answering one API call, the requests it got are kept.
+type fakeserver struct {
+	t       *testing.T
+	method  string
+	path    string
+	reply   func(w http.ResponseWriter, r *http.Request)
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+// Start a server for method and path under Apipath, closed when the test
+// ends.
+func newfake(t *testing.T, method string, path string, reply func(w http.ResponseWriter, r *http.Request)) (*fakeserver, *Client) {
+	f := &fakeserver{t: t, method: method, path: Apipath + path, reply: reply}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, New(srv.URL + "/")
+}
+
+func (f *fakeserver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.headers = append(f.headers, r.Header.Clone())
+	f.mu.Unlock()
+	if (r.Method != f.method) || (r.URL.Path != f.path) {
+		f.t.Errorf("request %s %s, want %s %s", r.Method, r.URL.Path, f.method, f.path)
+		http.NotFound(w, r)
+		return
+	}
+	f.reply(w, r)
+}
+
+// Header of the last request
+func (f *fakeserver) header() http.Header {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.headers[len(f.headers)-1]
+}
+
+// Reply v as JSON with status.
+func writejson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Decode the JSON body of r into v.
+func readjson(t *testing.T, r *http.Request, v interface{}) {
+	if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type %q, want application/json", ct)
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		t.Errorf("request body: %v", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	f, c := newfake(t, http.MethodPost, "/discoveries", func(w http.ResponseWriter, r *http.Request) {
+		var req Discoveryrequest
+		readjson(t, r, &req)
+		if req.Index != 4 || req.Targets != "192.168.1.20:1024" {
+			t.Errorf("request %+v", req)
+		}
+		writejson(w, http.StatusCreated, Discovery{ID: "d1", Index: req.Index, Targets: req.Targets,
+			Boards: []newopenhpsdr.Hpsdrboard{{Macaddress: testmac, Board: "HERMES", Baddress: "192.168.1.20:1024"}}})
+	})
+
+	d, err := c.Discover(context.Background(), Discoveryrequest{Index: 4, Targets: "192.168.1.20:1024"})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if d.ID != "d1" || len(d.Boards) != 1 || d.Boards[0].Macaddress != testmac {
+		t.Errorf("Discover got %+v", d)
+	}
+	if a := f.header().Get("Accept"); a != "application/json" {
+		t.Errorf("Accept %q, want application/json", a)
+	}
+}
+
+func TestSetip(t *testing.T) {
+	_, c := newfake(t, http.MethodPut, "/boards/"+testmac+"/ip", func(w http.ResponseWriter, r *http.Request) {
+		var req Setiprequest
+		readjson(t, r, &req)
+		if req.Address != "192.168.1.30" {
+			t.Errorf("request %+v", req)
+		}
+		writejson(w, http.StatusOK, newopenhpsdr.SetIPmessage{Macaddress: testmac, Oldaddress: "192.168.1.20:1024", Newaddress: req.Address,
+			Board: &newopenhpsdr.Hpsdrboard{Macaddress: testmac, Baddress: "192.168.1.30:1024"}})
+	})
+
+	msg, err := c.Setip(context.Background(), testmac, Setiprequest{Index: 4, Address: "192.168.1.30"})
+	if err != nil {
+		t.Fatalf("Setip: %v", err)
+	}
+	if msg.Newaddress != "192.168.1.30" || msg.Board == nil || msg.Board.Baddress != "192.168.1.30:1024" {
+		t.Errorf("Setip got %+v", msg)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	img := strings.Repeat("\xff", 64) + "firmware"
+	_, c := newfake(t, http.MethodPost, "/firmware", func(w http.ResponseWriter, r *http.Request) {
+		file, hdr, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		dta, _ := io.ReadAll(file)
+		if hdr.Filename != "Hermes_v10.4.rbf" || string(dta) != img {
+			t.Errorf("uploaded %s of %d bytes", hdr.Filename, len(dta))
+		}
+		writejson(w, http.StatusCreated, Firmware{Name: hdr.Filename, Sha256: "abc123", Board: "HERMES", Firmware: "10.4"})
+	})
+
+	fw, err := c.Upload(context.Background(), "/some/dir/Hermes_v10.4.rbf", strings.NewReader(img))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if fw.Sha256 != "abc123" || fw.Name != "Hermes_v10.4.rbf" {
+		t.Errorf("Upload got %+v", fw)
+	}
+}
+
+func TestProgram(t *testing.T) {
+	_, c := newfake(t, http.MethodPost, "/jobs", func(w http.ResponseWriter, r *http.Request) {
+		var req Jobrequest
+		readjson(t, r, &req)
+		if req.Mac != testmac || req.Rbf != "abc123" || !req.Verify || req.Force {
+			t.Errorf("request %+v", req)
+		}
+		writejson(w, http.StatusAccepted, Job{ID: "j1", State: "queued", Rbffile: "Hermes_v10.4.rbf", Verify: req.Verify})
+	})
+
+	j, err := c.Program(context.Background(), Jobrequest{Index: 4, Mac: testmac, Rbf: "abc123", Verify: true})
+	if err != nil {
+		t.Fatalf("Program: %v", err)
+	}
+	if j.ID != "j1" || j.State != "queued" || j.Finished() {
+		t.Errorf("Program got %+v", j)
+	}
+}
+
+func TestWait(t *testing.T) {
+	states := []string{"queued", "erasing", "programming", "verifying", "done"}
+	var mu sync.Mutex
+	polls := 0
+	_, c := newfake(t, http.MethodGet, "/jobs/j1", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		st := states[polls]
+		polls++
+		mu.Unlock()
+		writejson(w, http.StatusOK, Job{ID: "j1", State: st, Progress: Progress{State: st}})
+	})
+
+	j, err := c.Wait(context.Background(), "j1", time.Millisecond)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if j.State != "done" || polls != len(states) {
+		t.Errorf("Wait returned %s after %d polls, want done after %d", j.State, polls, len(states))
+	}
+}
+
+func TestWaitCanceled(t *testing.T) {
+	_, c := newfake(t, http.MethodGet, "/jobs/j1", func(w http.ResponseWriter, r *http.Request) {
+		writejson(w, http.StatusOK, Job{ID: "j1", State: "programming"})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Wait(ctx, "j1", 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait of a job that never ends: got %v, want the context error", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		status int
+		body   string
+		code   string
+		is     error
+	}{
+		{"timeout", http.StatusGatewayTimeout, `{"status":504,"code":"timeout","error":"no reply from the board"}`, "timeout", newopenhpsdr.ErrTimeout},
+		{"file", http.StatusBadRequest, `{"status":400,"code":"file","error":"not an RBF file"}`, "file", newopenhpsdr.ErrFileInvalid},
+		{"conflict", http.StatusConflict, `{"status":409,"code":"conflict","error":"board busy"}`, "conflict", nil},
+		{"not json", http.StatusBadGateway, `<html>proxy error</html>`, "failed", nil},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, cl := newfake(t, http.MethodGet, "/jobs/j1", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(c.status)
+				io.WriteString(w, c.body)
+			})
+
+			_, err := cl.Job(context.Background(), "j1")
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("got %v, want an *Error", err)
+			}
+			if e.Status != c.status || e.Code != c.code {
+				t.Errorf("got status %d code %s, want %d %s", e.Status, e.Code, c.status, c.code)
+			}
+			if (c.is != nil) && !errors.Is(err, c.is) {
+				t.Errorf("errors.Is(%v, %v) is false", err, c.is)
+			}
+			if (c.is == nil) && (errors.Is(err, newopenhpsdr.ErrTimeout) || errors.Is(err, newopenhpsdr.ErrFileInvalid)) {
+				t.Errorf("%v matches a library error", err)
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	f, c := newfake(t, http.MethodDelete, "/firmware/abc123", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := c.Deleteimage(context.Background(), "abc123"); err != nil {
+		t.Fatalf("Deleteimage: %v", err)
+	}
+	if x := f.header().Get("X-Requested-With"); x != "hpsdrclient" {
+		t.Errorf("X-Requested-With %q, want hpsdrclient", x)
+	}
+	if a := f.header().Get("Authorization"); a != "" {
+		t.Errorf("Authorization %q sent without a token", a)
+	}
+
+	c.Token = "s3cret"
+	if err := c.Deleteimage(context.Background(), "abc123"); err != nil {
+		t.Fatalf("Deleteimage: %v", err)
+	}
+	if a := f.header().Get("Authorization"); a != "Bearer s3cret" {
+		t.Errorf("Authorization %q, want Bearer s3cret", a)
+	}
+	if x := f.header().Get("X-Requested-With"); x != "hpsdrclient" {
+		t.Errorf("X-Requested-With %q with a token, want hpsdrclient", x)
+	}
+}
@@ -0,0 +1,161 @@
+// Erase and program several boards at once
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// Boards programmed at once when the caller does not say.
+const Batchparallel int = 4
+
+// One board of a batch.  Erase and Program open their own UDP socket on
+// Addr, so with port 0 every board is talked to from its own port.
+type Batchjob struct {
+	Addr  string // local address to send from, such as 192.168.1.10:0
+	Board Hpsdrboard
+	Rbf   string
+	Erase bool // erase the flash before programming
+	Force bool // program a file whose manifest does not match
+}
+
+// Outcome of one board of a batch
+type Batchresult struct {
+	Macaddress string  `json:"macaddress"`
+	Board      string  `json:"board"`
+	Baddress   string  `json:"baddress"`
+	Rbf        string  `json:"rbf"`
+	State      string  `json:"state"` // queued, erasing, programming, done, failed, canceled
+	Blocks     uint32  `json:"blocks"`
+	Retries    int     `json:"retries"`
+	Seconds    float64 `json:"seconds"`
+	Err        error   `json:"-"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Results of a batch, in the order of the jobs
+type Batchsummary struct {
+	Boards   int           `json:"boards"`
+	Done     int           `json:"done"`
+	Failed   int           `json:"failed"`
+	Canceled int           `json:"canceled"`
+	Skipped  int           `json:"skipped"` // left out by the caller, such as boards the file is not for
+	Seconds  float64       `json:"seconds"`
+	Results  []Batchresult `json:"results"`
+}
+
+// Erase and program every board of jobs, at most parallel at once.  obs
+// gets the progress of every board, from several goroutines at the same
+// time, Progress.Macaddress tells them apart.  Boards not started when
+// ctx ends are reported canceled.
+func Programall(ctx context.Context, jobs []Batchjob, parallel int, debug string, obs Observer) (sum Batchsummary) {
+	if parallel < 1 {
+		parallel = Batchparallel
+	}
+	start := time.Now()
+	log.Printf("             Batch: %d boards, %d at once\n", len(jobs), parallel)
+
+	// each board reports its result once it ends, only this goroutine
+	// writes sum
+	type finished struct {
+		i   int
+		res Batchresult
+	}
+	done := make(chan finished)
+	slots := make(chan struct{}, parallel)
+	for i := range jobs {
+		go func(i int, job Batchjob) {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				res := newbatchresult(job)
+				res.State = "canceled"
+				res.Err = ctx.Err()
+				res.Error = res.Err.Error()
+				done <- finished{i, res}
+				return
+			}
+			res := runbatchjob(ctx, job, debug, obs)
+			<-slots
+			done <- finished{i, res}
+		}(i, jobs[i])
+	}
+
+	sum.Boards = len(jobs)
+	sum.Results = make([]Batchresult, len(jobs))
+	for range jobs {
+		f := <-done
+		sum.Results[f.i] = f.res
+		switch f.res.State {
+		case "done":
+			sum.Done++
+		case "canceled":
+			sum.Canceled++
+		default:
+			sum.Failed++
+		}
+	}
+	sum.Seconds = time.Since(start).Seconds()
+	log.Printf("             Batch: %d boards, %d done, %d failed, %d canceled in %.1f seconds\n", sum.Boards, sum.Done, sum.Failed, sum.Canceled, sum.Seconds)
+	return sum
+}
+
+// Result of a job not started yet
+func newbatchresult(job Batchjob) Batchresult {
+	return Batchresult{Macaddress: job.Board.Macaddress, Board: job.Board.Board, Baddress: job.Board.Baddress, Rbf: job.Rbf, State: "queued"}
+}
+
+// Check the file, then erase and program one board of a batch.  Only the
+// goroutine running the job touches its result.
+func runbatchjob(ctx context.Context, job Batchjob, debug string, obs Observer) (res Batchresult) {
+	res = newbatchresult(job)
+	start := time.Now()
+	track := func(p Progress) {
+		if p.Kind == Programdone || p.Kind == Blockacked {
+			res.Blocks = p.Blocks
+		}
+		res.Retries = p.Retries
+		if obs != nil {
+			obs(p)
+		}
+	}
+
+	err := ctx.Err()
+	if err == nil {
+		// a bad file must fail before the flash is erased
+		_, err = Validaterbf(job.Rbf, job.Board)
+	}
+	if err == nil {
+		if _, _, err = Checkmanifest(job.Rbf, job.Board); job.Force {
+			err = nil
+		}
+	}
+	if (err == nil) && job.Erase {
+		res.State = "erasing"
+		err = EraseProgress(ctx, job.Addr, job.Board, debug, track)
+	}
+	if err == nil {
+		res.State = "programming"
+		err = ProgramProgress(ctx, job.Addr, job.Board, job.Rbf, job.Force, debug, track)
+	}
+
+	res.Seconds = time.Since(start).Seconds()
+	switch {
+	case err == nil:
+		res.State = "done"
+	case errors.Is(err, context.Canceled) && (res.State == "queued"):
+		res.State = "canceled"
+	default:
+		res.State = "failed"
+	}
+	if err != nil {
+		res.Err = err
+		res.Error = err.Error()
+		log.Printf("             Batch: %s (%s) %s: %v\n", res.Board, res.Macaddress, res.State, err)
+	}
+	return res
+}
@@ -0,0 +1,150 @@
+// Registry of the board types answering discovery
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Capabilities of one board type, keyed by the board ID byte of the
+// discovery reply.
+type Boardinfo struct {
+	ID         byte     `json:"id"`
+	Name       string   `json:"name"`       // reported as Hpsdrboard.Board
+	Models     []string `json:"models"`     // radios built on the board
+	Rbfpattern string   `json:"rbfpattern"` // regular expression matching its RBF file names
+	Flashsize  int64    `json:"flashsize"`  // bytes of configuration flash
+	Receivers  int      `json:"receivers"`  // most receivers the firmware offers
+	Protocols  []int    `json:"protocols"`  // openHPSDR protocol versions
+	rbf        *regexp.Regexp
+}
+
+// Name reported for a board ID that is not registered
+const Unknownboard string = "Unknown"
+
+var (
+	boardmu sync.RWMutex
+	boards  = make(map[byte]Boardinfo)
+)
+
+func init() {
+	for _, b := range []Boardinfo{
+		{ID: 0, Name: "ATLAS", Models: []string{"Metis"}, Rbfpattern: `(?i)(atlas|metis)`, Flashsize: 2 << 20, Receivers: 4, Protocols: []int{1}},
+		{ID: 1, Name: "HERMES", Models: []string{"ANAN-10", "ANAN-100"}, Rbfpattern: `(?i)hermes`, Flashsize: 2 << 20, Receivers: 4, Protocols: []int{1, 2}},
+		{ID: 2, Name: "HERMES-II", Models: []string{"ANAN-10E", "ANAN-100B"}, Rbfpattern: `(?i)(hermes[-_ ]?ii|anan[-_ ]?10e|anan[-_ ]?100b)`, Flashsize: 2 << 20, Receivers: 2, Protocols: []int{1, 2}},
+		{ID: 3, Name: "ANGELIA", Models: []string{"ANAN-100D"}, Rbfpattern: `(?i)angelia`, Flashsize: 8 << 20, Receivers: 7, Protocols: []int{1, 2}},
+		{ID: 4, Name: "ORION", Models: []string{"ANAN-200D"}, Rbfpattern: `(?i)orion`, Flashsize: 8 << 20, Receivers: 7, Protocols: []int{1, 2}},
+		{ID: 5, Name: "ORION-MKII", Models: []string{"ANAN-7000DLE", "ANAN-8000DLE"}, Rbfpattern: `(?i)orion[-_ ]?mk[-_ ]?ii`, Flashsize: 16 << 20, Receivers: 7, Protocols: []int{1, 2}},
+		{ID: 6, Name: "HERMES-LITE", Models: []string{"Hermes-Lite 2"}, Rbfpattern: `(?i)hermes[-_ ]?lite`, Flashsize: 2 << 20, Receivers: 4, Protocols: []int{1}},
+		{ID: 10, Name: "SATURN", Models: []string{"ANAN-G2"}, Rbfpattern: `(?i)saturn`, Flashsize: 32 << 20, Receivers: 10, Protocols: []int{2}},
+	} {
+		if err := Registerboard(b); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Add or replace the board type with b.ID.
+func Registerboard(b Boardinfo) error {
+	if b.Name == "" {
+		return fmt.Errorf("board %d: empty name", b.ID)
+	}
+	if b.Rbfpattern == "" {
+		b.Rbfpattern = "(?i)" + regexp.QuoteMeta(b.Name)
+	}
+	re, err := regexp.Compile(b.Rbfpattern)
+	if err != nil {
+		return fmt.Errorf("board %d (%s): %v", b.ID, b.Name, err)
+	}
+	b.rbf = re
+
+	boardmu.Lock()
+	defer boardmu.Unlock()
+	boards[b.ID] = b
+	return nil
+}
+
+// Look up a board type by the discovery board ID.
+func Boardbyid(id byte) (b Boardinfo, ok bool) {
+	boardmu.RLock()
+	defer boardmu.RUnlock()
+	b, ok = boards[id]
+	return b, ok
+}
+
+// Look up a board type by its name, such as "ANGELIA".
+func Boardbyname(name string) (b Boardinfo, ok bool) {
+	boardmu.RLock()
+	defer boardmu.RUnlock()
+	for _, b := range boards {
+		if strings.EqualFold(b.Name, name) {
+			return b, true
+		}
+	}
+	return b, false
+}
+
+// Every registered board type, by ID.
+func Boardlist() (bl []Boardinfo) {
+	boardmu.RLock()
+	for _, b := range boards {
+		bl = append(bl, b)
+	}
+	boardmu.RUnlock()
+	sort.Slice(bl, func(i, j int) bool { return bl[i].ID < bl[j].ID })
+	return bl
+}
+
+// Name reported for a board ID.
+func Boardname(id byte) string {
+	if b, ok := Boardbyid(id); ok {
+		return b.Name
+	}
+	return Unknownboard
+}
+
+// Length of the longest match of the RBF pattern in the file name, 0 for none.
+func (b Boardinfo) Rbfmatch(filename string) int {
+	re := b.rbf
+	if re == nil {
+		var err error
+		if re, err = regexp.Compile(b.Rbfpattern); err != nil {
+			return 0
+		}
+	}
+	n := 0
+	for _, m := range re.FindAllStringIndex(filepath.Base(filename), -1) {
+		if m[1]-m[0] > n {
+			n = m[1] - m[0]
+		}
+	}
+	return n
+}
+
+// Board type an RBF file name is meant for.  The longest match wins, so
+// Hermes_II_v10.4.rbf is for HERMES-II and not HERMES.
+func Rbfboard(filename string) (b Boardinfo, ok bool) {
+	best := 0
+	for _, r := range Boardlist() {
+		if n := r.Rbfmatch(filename); n > best {
+			b, ok, best = r, true, n
+		}
+	}
+	return b, ok
+}
+
+// Report whether the RBF file name is meant for the discovered board.
+// Boards missing from the registry fall back to the board name.
+func Rbfforboard(str Hpsdrboard, filename string) bool {
+	if _, ok := Boardbyname(str.Board); ok {
+		b, ok := Rbfboard(filename)
+		return ok && strings.EqualFold(b.Name, str.Board)
+	}
+	return strings.Contains(strings.ToLower(filepath.Base(filename)), strings.ToLower(str.Board))
+}
@@ -0,0 +1,307 @@
+// Encode and decode the protocol 2 programming packets
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// Packet sizes
+const (
+	Packetlen  int = 60
+	Blocklen   int = 256
+	Programlen int = 9 + Blocklen
+)
+
+// Command byte, offset 4 of every packet sent to a board
+const (
+	Cmddiscover byte = 0x02
+	Cmdsetip    byte = 0x03
+	Cmderase    byte = 0x04
+	Cmdprogram  byte = 0x05
+)
+
+// Reply byte, offset 4 of every packet sent by a board
+const (
+	Replyidle    byte = 0x02 // discovery, board not running
+	Replyrunning byte = 0x03 // discovery, board running
+	Replyerase   byte = 0x03 // erase started or finished
+	Replyprogram byte = 0x04 // program block received
+)
+
+// Check the length and command byte of a received packet, every packet
+// type has one fixed size.
+func checkpacket(name string, data []byte, size int, cmd ...byte) error {
+	if len(data) != size {
+		return fmt.Errorf("%w: %s is %d bytes, want %d", ErrPacketInvalid, name, len(data), size)
+	}
+	for _, c := range cmd {
+		if data[4] == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s has type 0x%02x", ErrPacketInvalid, name, data[4])
+}
+
+// DiscoveryRequest is broadcast to find the boards on a network.
+type DiscoveryRequest struct {
+	Sequence uint32
+}
+
+func (p DiscoveryRequest) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, Packetlen)
+	binary.BigEndian.PutUint32(buf, p.Sequence)
+	buf[4] = Cmddiscover
+	return buf, nil
+}
+
+func (p *DiscoveryRequest) UnmarshalBinary(data []byte) error {
+	if err := checkpacket("discovery request", data, Packetlen, Cmddiscover); err != nil {
+		return err
+	}
+	p.Sequence = binary.BigEndian.Uint32(data[0:4])
+	return nil
+}
+
+// DiscoveryReply is the answer of one board to a DiscoveryRequest.
+type DiscoveryReply struct {
+	Sequence  uint32
+	Running   bool
+	MAC       net.HardwareAddr
+	BoardID   byte
+	Protocol  byte
+	Firmware  byte
+	Mercury   [4]byte
+	Penelope  byte
+	Metis     byte
+	Receivers byte
+	Freqinput byte
+	Iqformat  byte
+}
+
+func (p DiscoveryReply) MarshalBinary() ([]byte, error) {
+	if len(p.MAC) != 6 {
+		return nil, fmt.Errorf("%w: discovery reply MAC %v", ErrPacketInvalid, p.MAC)
+	}
+	buf := make([]byte, Packetlen)
+	binary.BigEndian.PutUint32(buf, p.Sequence)
+	buf[4] = Replyidle
+	if p.Running {
+		buf[4] = Replyrunning
+	}
+	copy(buf[5:11], p.MAC)
+	buf[11] = p.BoardID
+	buf[12] = p.Protocol
+	buf[13] = p.Firmware
+	copy(buf[14:18], p.Mercury[:])
+	buf[18] = p.Penelope
+	buf[19] = p.Metis
+	buf[20] = p.Receivers
+	buf[21] = p.Freqinput
+	buf[22] = p.Iqformat
+	return buf, nil
+}
+
+func (p *DiscoveryReply) UnmarshalBinary(data []byte) error {
+	if err := checkpacket("discovery reply", data, Packetlen, Replyidle, Replyrunning); err != nil {
+		return err
+	}
+	p.Sequence = binary.BigEndian.Uint32(data[0:4])
+	p.Running = data[4] == Replyrunning
+	p.MAC = append(net.HardwareAddr(nil), data[5:11]...)
+	p.BoardID = data[11]
+	p.Protocol = data[12]
+	p.Firmware = data[13]
+	copy(p.Mercury[:], data[14:18])
+	p.Penelope = data[18]
+	p.Metis = data[19]
+	p.Receivers = data[20]
+	p.Freqinput = data[21]
+	p.Iqformat = data[22]
+	return nil
+}
+
+// Format a version byte as major.minor
+func Versionstring(v byte) string {
+	return fmt.Sprintf("%d.%d", v/10, v%10)
+}
+
+// Hpsdrboard converts the reply from address ad, received on the local
+// address addrStr, into the board record used by the programmers.
+func (p DiscoveryReply) Hpsdrboard(ad *net.UDPAddr, addrStr string) (str Hpsdrboard) {
+	str.Mac = append([]byte(nil), p.MAC...)
+	str.Macaddress = fmt.Sprintf("%x:%x:%x:%x:%x:%x", p.MAC[0], p.MAC[1], p.MAC[2], p.MAC[3], p.MAC[4], p.MAC[5])
+	str.Pcaddress = addrStr
+
+	if p.Running {
+		str.Status = "running"
+	} else {
+		str.Status = "not running"
+	}
+
+	str.Boardid = int(p.BoardID)
+	str.Board = Boardname(p.BoardID)
+
+	str.Protocol = Versionstring(p.Protocol)
+	str.Firmware = Versionstring(p.Firmware)
+	str.Atlas.Mercury1 = Versionstring(p.Mercury[0])
+	str.Atlas.Mercury2 = Versionstring(p.Mercury[1])
+	str.Atlas.Mercury3 = Versionstring(p.Mercury[2])
+	str.Atlas.Mercury4 = Versionstring(p.Mercury[3])
+	str.Atlas.Penelope = Versionstring(p.Penelope)
+	str.Atlas.Metis = Versionstring(p.Metis)
+
+	str.Receivers = int(p.Receivers)
+	if p.Freqinput == 0 {
+		str.Freqinput = "Frequency"
+	} else {
+		str.Freqinput = "Phase_word"
+	}
+
+	if p.Iqformat == 0 {
+		str.Iqdata = "Big-Endian IQ in 3 byte format"
+	} else if p.Iqformat == 1 {
+		str.Iqdata = "Little-Endian"
+	} else if p.Iqformat == 2 {
+		str.Iqdata = "3 Byte format"
+	} else if p.Iqformat == 3 {
+		str.Iqdata = "1 Float format"
+	} else if p.Iqformat == 4 {
+		str.Iqdata = "1 Double format"
+	}
+
+	if ad != nil {
+		str.Baddress = ad.String()
+	}
+	return str
+}
+
+// SetIPRequest asks the board with MAC to use a new IPv4 address,
+// 255.255.255.255 returns it to DHCP.
+type SetIPRequest struct {
+	Sequence uint32
+	MAC      net.HardwareAddr
+	IP       net.IP
+}
+
+func (p SetIPRequest) MarshalBinary() ([]byte, error) {
+	if len(p.MAC) != 6 {
+		return nil, fmt.Errorf("%w: set IP MAC %v", ErrPacketInvalid, p.MAC)
+	}
+	ip := p.IP.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("%w: set IP address %v is not IPv4", ErrPacketInvalid, p.IP)
+	}
+	buf := make([]byte, Packetlen)
+	binary.BigEndian.PutUint32(buf, p.Sequence)
+	buf[4] = Cmdsetip
+	copy(buf[5:11], p.MAC)
+	copy(buf[11:15], ip)
+	return buf, nil
+}
+
+func (p *SetIPRequest) UnmarshalBinary(data []byte) error {
+	if err := checkpacket("set IP request", data, Packetlen, Cmdsetip); err != nil {
+		return err
+	}
+	p.Sequence = binary.BigEndian.Uint32(data[0:4])
+	p.MAC = append(net.HardwareAddr(nil), data[5:11]...)
+	p.IP = net.IPv4(data[11], data[12], data[13], data[14]).To4()
+	return nil
+}
+
+// EraseRequest asks a board to erase its flash memory.
+type EraseRequest struct {
+	Sequence uint32
+}
+
+func (p EraseRequest) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, Packetlen)
+	binary.BigEndian.PutUint32(buf, p.Sequence)
+	buf[4] = Cmderase
+	return buf, nil
+}
+
+func (p *EraseRequest) UnmarshalBinary(data []byte) error {
+	if err := checkpacket("erase request", data, Packetlen, Cmderase); err != nil {
+		return err
+	}
+	p.Sequence = binary.BigEndian.Uint32(data[0:4])
+	return nil
+}
+
+// EraseAck is sent by the board once when the erase starts and once
+// when it finishes.
+type EraseAck struct {
+	Sequence uint32
+	MAC      net.HardwareAddr
+}
+
+func (p EraseAck) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, Packetlen)
+	binary.BigEndian.PutUint32(buf, p.Sequence)
+	buf[4] = Replyerase
+	copy(buf[5:11], p.MAC)
+	return buf, nil
+}
+
+func (p *EraseAck) UnmarshalBinary(data []byte) error {
+	if err := checkpacket("erase reply", data, Packetlen, Replyerase); err != nil {
+		return err
+	}
+	p.Sequence = binary.BigEndian.Uint32(data[0:4])
+	p.MAC = append(net.HardwareAddr(nil), data[5:11]...)
+	return nil
+}
+
+// ProgramData carries one 256 byte block of the RBF image.
+type ProgramData struct {
+	Sequence uint32
+	Blocks   uint32
+	Data     [256]byte
+}
+
+func (p ProgramData) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, Programlen)
+	binary.BigEndian.PutUint32(buf, p.Sequence)
+	buf[4] = Cmdprogram
+	binary.BigEndian.PutUint32(buf[5:9], p.Blocks)
+	copy(buf[9:], p.Data[:])
+	return buf, nil
+}
+
+func (p *ProgramData) UnmarshalBinary(data []byte) error {
+	if err := checkpacket("program block", data, Programlen, Cmdprogram); err != nil {
+		return err
+	}
+	p.Sequence = binary.BigEndian.Uint32(data[0:4])
+	p.Blocks = binary.BigEndian.Uint32(data[5:9])
+	copy(p.Data[:], data[9:Programlen])
+	return nil
+}
+
+// ProgramAck is sent by the board for every ProgramData block received.
+type ProgramAck struct {
+	Sequence uint32
+	MAC      net.HardwareAddr
+}
+
+func (p ProgramAck) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, Packetlen)
+	binary.BigEndian.PutUint32(buf, p.Sequence)
+	buf[4] = Replyprogram
+	copy(buf[5:11], p.MAC)
+	return buf, nil
+}
+
+func (p *ProgramAck) UnmarshalBinary(data []byte) error {
+	if err := checkpacket("program reply", data, Packetlen, Replyprogram); err != nil {
+		return err
+	}
+	p.Sequence = binary.BigEndian.Uint32(data[0:4])
+	p.MAC = append(net.HardwareAddr(nil), data[5:11]...)
+	return nil
+}
@@ -0,0 +1,103 @@
+// Round trip tests of the protocol 2 packet codec
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"encoding"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+var testmac = net.HardwareAddr{0x00, 0x1c, 0xc0, 0xa2, 0x13, 0x01}
+
+// A packet and a new empty value of its type to decode it into
+type codeccase struct {
+	name string
+	in   encoding.BinaryMarshaler
+	out  encoding.BinaryUnmarshaler
+	size int
+	cmd  byte
+}
+
+func codeccases() []codeccase {
+	var block [256]byte
+	for i := range block {
+		block[i] = byte(i)
+	}
+	return []codeccase{
+		{"discovery request", DiscoveryRequest{Sequence: 7}, &DiscoveryRequest{}, Packetlen, Cmddiscover},
+		{"discovery reply", DiscoveryReply{Sequence: 1, MAC: testmac, BoardID: 1, Protocol: 38, Firmware: 104,
+			Mercury: [4]byte{1, 2, 3, 4}, Penelope: 5, Metis: 6, Receivers: 4, Freqinput: 1, Iqformat: 1}, &DiscoveryReply{}, Packetlen, Replyidle},
+		{"discovery reply running", DiscoveryReply{Running: true, MAC: testmac, BoardID: 5}, &DiscoveryReply{}, Packetlen, Replyrunning},
+		{"set IP request", SetIPRequest{Sequence: 3, MAC: testmac, IP: net.IPv4(192, 168, 1, 50).To4()}, &SetIPRequest{}, Packetlen, Cmdsetip},
+		{"set IP request dhcp", SetIPRequest{MAC: testmac, IP: Dhcpaddress.To4()}, &SetIPRequest{}, Packetlen, Cmdsetip},
+		{"erase request", EraseRequest{Sequence: 2}, &EraseRequest{}, Packetlen, Cmderase},
+		{"erase ack", EraseAck{Sequence: 0, MAC: testmac}, &EraseAck{}, Packetlen, Replyerase},
+		{"program data", ProgramData{Sequence: 390, Blocks: 391, Data: block}, &ProgramData{}, Programlen, Cmdprogram},
+		{"program ack", ProgramAck{Sequence: 390, MAC: testmac}, &ProgramAck{}, Packetlen, Replyprogram},
+	}
+}
+
+func TestCodecRoundtrip(t *testing.T) {
+	for _, c := range codeccases() {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := c.in.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary: %v", err)
+			}
+			if len(b) != c.size {
+				t.Fatalf("packet is %d bytes, want %d", len(b), c.size)
+			}
+			if b[4] != c.cmd {
+				t.Fatalf("type byte 0x%02x, want 0x%02x", b[4], c.cmd)
+			}
+			if err := c.out.UnmarshalBinary(b); err != nil {
+				t.Fatalf("UnmarshalBinary: %v", err)
+			}
+			got := reflect.ValueOf(c.out).Elem().Interface()
+			if !reflect.DeepEqual(got, c.in) {
+				t.Errorf("round trip\n got %+v\nwant %+v", got, c.in)
+			}
+		})
+	}
+}
+
+func TestCodecRejects(t *testing.T) {
+	for _, c := range codeccases() {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := c.in.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary: %v", err)
+			}
+			bad := map[string][]byte{
+				"short":     b[:len(b)-1],
+				"empty":     nil,
+				"over-long": append(append([]byte(nil), b...), 0),
+			}
+			wrong := append([]byte(nil), b...)
+			wrong[4] = 0x7f
+			bad["wrong type"] = wrong
+			for what, data := range bad {
+				err := c.out.UnmarshalBinary(data)
+				if !errors.Is(err, ErrPacketInvalid) {
+					t.Errorf("%s packet: got %v, want ErrPacketInvalid", what, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCodecMarshalRejects(t *testing.T) {
+	for name, m := range map[string]encoding.BinaryMarshaler{
+		"discovery reply short MAC": DiscoveryReply{MAC: testmac[:5]},
+		"set IP without MAC":        SetIPRequest{IP: net.IPv4(10, 0, 0, 2)},
+		"set IP IPv6 address":       SetIPRequest{MAC: testmac, IP: net.ParseIP("fd00::2")},
+	} {
+		if _, err := m.MarshalBinary(); !errors.Is(err, ErrPacketInvalid) {
+			t.Errorf("%s: got %v, want ErrPacketInvalid", name, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Errors returned by the newopenhpsdr package
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel errors, test for them with errors.Is.
+var (
+	ErrTimeout          = errors.New("timed out waiting for the board")
+	ErrSequenceMismatch = errors.New("sequence number mismatch")
+	ErrBoardNotFound    = errors.New("board not found")
+	ErrFileInvalid      = errors.New("invalid RBF file")
+	ErrPacketInvalid    = errors.New("invalid packet")
+	ErrAddressInvalid   = errors.New("invalid board address")
+)
+
+// TimeoutError is returned when a board does not answer before the
+// operation deadline.
+type TimeoutError struct {
+	Op   string
+	Addr string
+	Wait time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: no reply from %s after %v", e.Op, e.Addr, e.Wait)
+}
+
+// Is matches ErrTimeout.
+func (e *TimeoutError) Is(target error) bool { return target == ErrTimeout }
+
+// Timeout reports true, so TimeoutError satisfies net.Error.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// Temporary reports true, the operation may be retried.
+func (e *TimeoutError) Temporary() bool { return true }
+
+// SequenceError is returned when a board acknowledges a program block
+// other than the one that was sent.
+type SequenceError struct {
+	Addr     string
+	Sent     uint32
+	Received uint32
+	Reply    byte
+}
+
+func (e *SequenceError) Error() string {
+	return fmt.Sprintf("program: sent block %d, %s replied %d (type %d)", e.Sent, e.Addr, e.Received, e.Reply)
+}
+
+// Is matches ErrSequenceMismatch.
+func (e *SequenceError) Is(target error) bool { return target == ErrSequenceMismatch }
+
+// ProgramError is returned when a Program run stops before the last
+// block, Err holds the reason.
+type ProgramError struct {
+	Addr     string
+	Lastgood int64 // last block acknowledged, -1 for none
+	Blocks   uint32
+	Retries  int
+	Err      error
+}
+
+func (e *ProgramError) Error() string {
+	return fmt.Sprintf("program %s stopped after block %d of %d, %d retries: %v", e.Addr, e.Lastgood, e.Blocks, e.Retries, e.Err)
+}
+
+func (e *ProgramError) Unwrap() error { return e.Err }
+
+// BoardError is returned when no board with the given MAC address answers.
+type BoardError struct {
+	Macaddress string
+	Addr       string
+}
+
+func (e *BoardError) Error() string {
+	if e.Addr == "" {
+		return fmt.Sprintf("board (%s) not found", e.Macaddress)
+	}
+	return fmt.Sprintf("board (%s) not found on %s", e.Macaddress, e.Addr)
+}
+
+// Is matches ErrBoardNotFound.
+func (e *BoardError) Is(target error) bool { return target == ErrBoardNotFound }
+
+// FileError is returned when an RBF file cannot be used.
+type FileError struct {
+	Filename string
+	Err      error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("rbf file %s: %v", e.Filename, e.Err)
+}
+
+func (e *FileError) Unwrap() error { return e.Err }
+
+// Is matches ErrFileInvalid.
+func (e *FileError) Is(target error) bool { return target == ErrFileInvalid }
+
+// AddressError is returned when an address cannot be given to a board.
+type AddressError struct {
+	Address string
+	Reason  string
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("address %s: %s", e.Address, e.Reason)
+}
+
+// Is matches ErrAddressInvalid.
+func (e *AddressError) Is(target error) bool { return target == ErrAddressInvalid }
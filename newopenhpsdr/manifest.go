@@ -0,0 +1,167 @@
+// Firmware manifests kept next to the RBF files
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest errors, test for them with errors.Is.
+var (
+	ErrChecksum         = errors.New("SHA-256 does not match the manifest")
+	ErrManifestMismatch = errors.New("manifest is for another board")
+)
+
+// Manifest describes one RBF file.  It is read from a JSON or YAML file
+// next to the RBF, see Manifestnames.
+type Manifest struct {
+	File     string `json:"file"`
+	Board    string `json:"board"`
+	Firmware string `json:"firmware"`
+	Sha256   string `json:"sha256"`
+	Notes    string `json:"notes"`
+	Path     string `json:"path"` // where the manifest was read from
+}
+
+// Names a manifest for the RBF file may have, in the order they are tried:
+// Angelia_v10.3.rbf.json, Angelia_v10.3.json, then the same with .yaml
+// and .yml.
+func Manifestnames(rbf string) (names []string) {
+	base := strings.TrimSuffix(rbf, filepath.Ext(rbf))
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		names = append(names, rbf+ext, base+ext)
+	}
+	return names
+}
+
+// Read the manifest of an RBF file, found is false when there is none.
+func Loadmanifest(rbf string) (m Manifest, found bool, er error) {
+	for _, name := range Manifestnames(rbf) {
+		dta, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return m, true, &FileError{Filename: name, Err: err}
+		}
+		if strings.HasSuffix(name, ".json") {
+			err = json.Unmarshal(dta, &m)
+		} else {
+			err = parseyaml(dta, &m)
+		}
+		if err != nil {
+			return m, true, &FileError{Filename: name, Err: fmt.Errorf("manifest: %v", err)}
+		}
+		m.Path = name
+		m.Sha256 = strings.ToLower(strings.TrimSpace(m.Sha256))
+		return m, true, nil
+	}
+	return m, false, nil
+}
+
+// SHA-256 of a file, in hex.
+func Filesha256(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Read the manifest of an RBF file and check the file hash, and the board
+// type when str.Board is set.  An RBF file without a manifest passes.
+func Checkmanifest(rbf string, str Hpsdrboard) (m Manifest, found bool, er error) {
+	m, found, er = Loadmanifest(rbf)
+	if er != nil || !found {
+		return m, found, er
+	}
+	log.Printf("          Manifest: %s board %s firmware %s\n", m.Path, m.Board, m.Firmware)
+
+	if m.Sha256 != "" {
+		sum, err := Filesha256(rbf)
+		if err != nil {
+			return m, found, &FileError{Filename: rbf, Err: err}
+		}
+		if sum != m.Sha256 {
+			return m, found, &FileError{Filename: rbf, Err: fmt.Errorf("%w: file %s, manifest %s", ErrChecksum, sum, m.Sha256)}
+		}
+	}
+
+	if (m.Board != "") && (str.Board != "") && !strings.EqualFold(m.Board, str.Board) {
+		return m, found, &FileError{Filename: rbf, Err: fmt.Errorf("%w: manifest %s, board %s", ErrManifestMismatch, m.Board, str.Board)}
+	}
+	return m, found, nil
+}
+
+// Read the flat YAML subset used by manifests: "key: value" lines,
+// comments, and "key: |" for an indented block of text.
+func parseyaml(dta []byte, m *Manifest) error {
+	fields := map[string]*string{
+		"file":     &m.File,
+		"board":    &m.Board,
+		"firmware": &m.Firmware,
+		"sha256":   &m.Sha256,
+		"notes":    &m.Notes,
+	}
+
+	var block *string
+	var lines []string
+	endblock := func() {
+		if block != nil {
+			*block = strings.Join(lines, "\n")
+			block, lines = nil, nil
+		}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(dta))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if block != nil && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.TrimSpace(line) == "") {
+			lines = append(lines, strings.TrimSpace(line))
+			continue
+		}
+		endblock()
+
+		t := strings.TrimSpace(line)
+		if (t == "") || strings.HasPrefix(t, "#") || (t == "---") {
+			continue
+		}
+		i := strings.Index(t, ":")
+		if i < 1 {
+			return fmt.Errorf("line %d: want key: value", n)
+		}
+		key := strings.ToLower(strings.TrimSpace(t[:i]))
+		val := strings.TrimSpace(t[i+1:])
+		f, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if (val == "|") || (val == ">") {
+			block = f
+			continue
+		}
+		*f = strings.Trim(val, "\"'")
+	}
+	endblock()
+	m.Notes = strings.TrimSpace(m.Notes)
+	return sc.Err()
+}
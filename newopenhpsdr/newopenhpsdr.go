@@ -7,6 +7,7 @@ package newopenhpsdr
 
 import (
 	"bufio"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -15,25 +16,35 @@ import (
 	"math"
 	"net"
 	"os"
-	"runtime"
-	"strconv"
 	"strings"
 	"time"
 )
 
 type Intface struct {
-	Intname   string `json:"intname"`
-	Matchname string `json:"matchname"`
-	Index     int    `json:"index"`
-	MAC       string `json:"mac"`
+	Intname   string     `json:"intname"`
+	Matchname string     `json:"matchname"`
+	Index     int        `json:"index"`
+	MAC       string     `json:"mac"`
+	Ipv4      string     `json:"ipv4"`
+	Ipv6      string     `json:"ipv6"`
+	Ipv4Bcast string     `json:"ipv4bcast"`
+	Netmask   string     `json:"netmask"`
+	Network   string     `json:"network"`
+	Ipv4addrs []Ipv4addr `json:"ipv4addrs"`
+}
+
+// One IPv4 address of an interface with its subnet.
+type Ipv4addr struct {
 	Ipv4      string `json:"ipv4"`
-	Ipv6      string `json:"ipv6"`
+	Netmask   string `json:"netmask"`
+	Network   string `json:"network"`
 	Ipv4Bcast string `json:"ipv4bcast"`
 }
 
 type Hpsdrboard struct {
 	Status     string `json:"status"`
 	Board      string `json:"board"`
+	Boardid    int    `json:"boardid"`
 	Baddress   string `json:"baddress"`
 	Atlas      Atlasboards
 	Pcaddress  string `json:"pcaddress"`
@@ -57,10 +68,12 @@ type Atlasboards struct {
 }
 
 type SetIPmessage struct {
-	Oldaddress string `json:"oldadress"`
-	Newaddress string `json:"newadress"`
-	Macaddress string `json:"macaddress"`
-	Message    string `json:"message"`
+	Oldaddress string      `json:"oldadress"`
+	Newaddress string      `json:"newadress"`
+	Macaddress string      `json:"macaddress"`
+	Message    string      `json:"message"`
+	Warning    string      `json:"warning,omitempty"` // see Checkip
+	Board      *Hpsdrboard `json:"board,omitempty"`   // the board answering at the new address
 }
 
 type Erasemessage struct {
@@ -81,9 +94,26 @@ type Programmessage struct {
 	Message       string `json:"message"`
 }
 
+// Default deadlines for each operation when the caller's context has none.
+var (
+	Setiptimeout  time.Duration = 10 * time.Second
+	Erasetimeout  time.Duration = 120 * time.Second
+	Packettimeout time.Duration = 1 * time.Second
+)
+
+// Number of times Program resends one block, and in a whole run, before
+// giving up.
+var (
+	Blockretries int = 5
+	Retrybudget  int = 100
+)
+
 //  format Intface struct for web output
 func Intfacetable(intf Intface) (str string) {
 	str = fmt.Sprintf("<tr><td align=\"right\"><b>%d:</b></td><td> %s (%s) (%s) (%s)</td></tr>\n", intf.Index, intf.Intname, intf.MAC, intf.Ipv4, intf.Ipv6)
+	for _, ad := range intf.Ipv4addrs {
+		str += fmt.Sprintf("<tr><td></td><td> (%s) netmask %s broadcast %s</td></tr>\n", ad.Network, ad.Netmask, ad.Ipv4Bcast)
+	}
 	return str
 }
 
@@ -128,6 +158,7 @@ func Atlasboardstable(brd Hpsdrboard) (str string) {
 func ResetHpsdrboard(str Hpsdrboard) Hpsdrboard {
 	str.Status = ""
 	str.Board = ""
+	str.Boardid = 0
 	str.Baddress = ""
 	str.Atlas.Mercury1 = ""
 	str.Atlas.Mercury2 = ""
@@ -147,6 +178,8 @@ func ResetHpsdrboard(str Hpsdrboard) Hpsdrboard {
 }
 
 // Determine the network interfaces connect to this machine.
+// The first IPv4 address of an interface fills Ipv4, Netmask, Network
+// and Ipv4Bcast, every IPv4 address is listed in Ipv4addrs.
 func Interfaces() (Intfc []Intface) {
 
 	intr, err := net.Interfaces()
@@ -168,43 +201,68 @@ func Interfaces() (Intfc []Intface) {
 		}
 
 		for j := range aad {
-			//	ip := net.ParseIP(aad[j].String())
-			str := aad[j].String()
-
-			if strings.Contains(str, ".") {
-				if runtime.GOOS == "windows" {
-					//_ = net.ParseIP(aad[j].String())
-					//Intfc[i].Ipv4 = aad[j].String()
-					//} else if runtime.GOOS == "darwin" {
-					//ip := net.ParseIP(aad[j].String())
-					//Intfc[i].Ipv4 = ip.String()
-					adstring := strings.Split(aad[j].String(), "/")
-					Intfc[i].Ipv4 = adstring[0]
-				} else {
-					ip, _, err := net.ParseCIDR(aad[j].String())
-					if err != nil {
-						log.Println("Parse CIDR error", err)
-					}
-					Intfc[i].Ipv4 = ip.String()
-				}
-
-				//str := strings.Split(Intfc[i].Ipv4, ".")
-				var ipd []string
-				//ipd = append(ipd, str[0], str[1], str[2], "255")
-				ipd = append(ipd, "255", "255", "255", "255")
-				Intfc[i].Ipv4Bcast = strings.Join(ipd, ".")
-			} else {
-				ip, _, err := net.ParseCIDR(aad[j].String())
+			var ipn *net.IPNet
+			switch a := aad[j].(type) {
+			case *net.IPNet:
+				ipn = a
+			case *net.IPAddr:
+				// no prefix length reported, assume the classful mask
+				ipn = &net.IPNet{IP: a.IP, Mask: a.IP.DefaultMask()}
+			default:
+				ip, n, err := net.ParseCIDR(aad[j].String())
 				if err != nil {
 					log.Println("Parse CIDR error", err)
+					continue
+				}
+				ipn = &net.IPNet{IP: ip, Mask: n.Mask}
+			}
+
+			if ip4 := ipn.IP.To4(); ip4 != nil {
+				mask := ipn.Mask
+				if len(mask) == net.IPv6len {
+					mask = mask[12:]
+				}
+				if mask == nil {
+					mask = ip4.DefaultMask()
+				}
+				n := net.IPNet{IP: ip4.Mask(mask), Mask: mask}
+				ad := Ipv4addr{
+					Ipv4:      ip4.String(),
+					Netmask:   net.IP(mask).String(),
+					Network:   n.String(),
+					Ipv4Bcast: Ipv4broadcast(ip4, mask).String(),
 				}
-				Intfc[i].Ipv6 = ip.String()
+				Intfc[i].Ipv4addrs = append(Intfc[i].Ipv4addrs, ad)
+
+				if Intfc[i].Ipv4 == "" {
+					Intfc[i].Ipv4 = ad.Ipv4
+					Intfc[i].Netmask = ad.Netmask
+					Intfc[i].Network = ad.Network
+					Intfc[i].Ipv4Bcast = ad.Ipv4Bcast
+				}
+			} else if Intfc[i].Ipv6 == "" {
+				Intfc[i].Ipv6 = ipn.IP.String()
 			}
 		}
 	}
 	return Intfc
 }
 
+// Directed broadcast address of an IPv4 subnet.  Point to point /31 and
+// host /32 subnets have none, so the limited broadcast is used.
+func Ipv4broadcast(ip net.IP, mask net.IPMask) net.IP {
+	ip4 := ip.To4()
+	ones, bits := mask.Size()
+	if ip4 == nil || bits != 32 || ones >= 31 {
+		return net.IPv4bcast.To4()
+	}
+	bc := make(net.IP, net.IPv4len)
+	for k := range bc {
+		bc[k] = ip4[k] | ^mask[k]
+	}
+	return bc
+}
+
 /*
 func Intfacecompare(intr Intface, intf Intface) bool {
 	var match bool
@@ -256,7 +314,10 @@ func Commpacketreceive(l *net.UDPConn) (num int, ad *net.UDPAddr, rec []byte, er
 		num, ad, err = l.ReadFromUDP(rec)
 
 		if err != nil {
-			log.Println(" UDP Read error ", err)
+			if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+				log.Println(" UDP Read error ", err)
+			}
+			break
 		}
 		//log.Printf("%d::%v::%+v\n", num, ad, rec)
 
@@ -268,279 +329,517 @@ func Commpacketreceive(l *net.UDPConn) (num int, ad *net.UDPAddr, rec []byte, er
 	return num, ad, rec, err
 }
 
-func Makepacket(packettype string, seq int32, debug string) (buf []byte, err error) {
-	buf = make([]byte, 60, 60)
-	err = nil
-	switch packettype {
-	case "discover":
-		binary.BigEndian.PutUint32(buf, uint32(seq))
-		buf[4] = 0x02
-
-		for i := 5; i < 60; i++ {
-			buf = append(buf, 0x00)
+// Send a packet, returning a TimeoutError when the context deadline
+// passes or the context error when it is canceled, as the receive does.
+func CommpacketsendContext(ctx context.Context, l *net.UDPConn, destStr string, snd []byte) (k int, err error) {
+	if err = ctx.Err(); err != nil {
+		return 0, ctxerror(ctx, "send", l, 0)
+	}
+	start := time.Now()
+	if d, ok := ctx.Deadline(); ok {
+		l.SetWriteDeadline(d)
+	} else {
+		l.SetWriteDeadline(time.Time{})
+	}
+	k, err = Commpacketsend(l, destStr, snd)
+	if err != nil {
+		if ctx.Err() != nil {
+			return k, ctxerror(ctx, "send", l, time.Since(start))
 		}
-		if strings.Contains(debug, "dec") {
-			log.Printf(" %d\n", buf)
-		} else if strings.Contains(debug, "hex") {
-			log.Printf(" %x\n", buf)
-		} else {
-			//log.Printf(" \n")
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return k, &TimeoutError{Op: "send", Addr: destStr, Wait: time.Since(start)}
 		}
-		return buf, err
+	}
+	return k, err
+}
 
-	case "erase":
-		binary.BigEndian.PutUint32(buf, uint32(seq))
-		buf[4] = 0x04
+// Receive one packet, returning a TimeoutError when the context deadline
+// passes or the context error when it is canceled.
+func CommpacketreceiveContext(ctx context.Context, l *net.UDPConn) (num int, ad *net.UDPAddr, rec []byte, err error) {
+	if err = ctx.Err(); err != nil {
+		return 0, nil, make([]byte, 60, 60), ctxerror(ctx, "receive", l, 0)
+	}
 
-		for i := 5; i < 60; i++ {
-			buf = append(buf, 0x00)
-		}
-		if strings.Contains(debug, "dec") {
-			log.Printf(" %d\n", buf)
-		} else if strings.Contains(debug, "hex") {
-			log.Printf(" %x\n", buf)
-		} else {
-			//log.Printf(" \n")
+	start := time.Now()
+	if d, ok := ctx.Deadline(); ok {
+		l.SetReadDeadline(d)
+	} else {
+		l.SetReadDeadline(time.Time{})
+	}
+
+	// wake the reader up if the context is canceled, and wait for the
+	// waker to finish so it cannot touch the deadline of the next read
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer func() {
+		close(stop)
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+			l.SetReadDeadline(time.Now())
+		case <-stop:
 		}
-		return buf, err
-	case "setip":
-		binary.BigEndian.PutUint32(buf, uint32(seq))
-		buf[4] = 0x03
+	}()
 
-		for i := 5; i < 60; i++ {
-			buf = append(buf, 0x00)
+	num, ad, rec, err = Commpacketreceive(l)
+	if err != nil {
+		if ctx.Err() != nil {
+			return num, ad, rec, ctxerror(ctx, "receive", l, time.Since(start))
 		}
-		if strings.Contains(debug, "dec") {
-			log.Printf(" %d\n", buf)
-		} else if strings.Contains(debug, "hex") {
-			log.Printf(" %x\n", buf)
-		} else {
-			//log.Printf(" \n")
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return num, ad, rec, &TimeoutError{Op: "receive", Addr: l.LocalAddr().String(), Wait: time.Since(start)}
 		}
-		return buf, err
+	}
+	return num, ad, rec, err
+}
+
+// Convert a finished context into the error returned to the caller.
+func ctxerror(ctx context.Context, op string, l *net.UDPConn, wait time.Duration) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return &TimeoutError{Op: op, Addr: l.LocalAddr().String(), Wait: wait}
+	}
+	return ctx.Err()
+}
+
+// Apply a default deadline when the context does not already have one.
+func withdefault(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+func Makepacket(packettype string, seq int32, debug string) (buf []byte, err error) {
+	switch packettype {
+	case "discover":
+		buf, err = DiscoveryRequest{Sequence: uint32(seq)}.MarshalBinary()
+	case "erase":
+		buf, err = EraseRequest{Sequence: uint32(seq)}.MarshalBinary()
+	case "setip":
+		// address and MAC are filled in by the caller
+		buf = make([]byte, Packetlen)
+		binary.BigEndian.PutUint32(buf, uint32(seq))
+		buf[4] = Cmdsetip
 	default:
 		log.Println("Unknown packettype", packettype)
+		return make([]byte, Packetlen), fmt.Errorf("%w: unknown packet type %q", ErrPacketInvalid, packettype)
 	}
+	Packetdebug(buf, debug)
 	return buf, err
 }
 
 func Makepacketprogram(ibf []byte, seq uint32, numblk uint32, debug string) (buf []byte, err error) {
-	buf = make([]byte, 265, 265)
-	err = nil
-
-	binary.BigEndian.PutUint32(buf, seq)
-	buf[4] = 0x05
-	binary.BigEndian.PutUint32(buf[5:9], numblk)
-	for i := 9; i < 265; i++ {
-		buf[i] = ibf[i-9]
+	if len(ibf) < Blocklen {
+		return make([]byte, Programlen), fmt.Errorf("%w: program block of %d bytes", ErrPacketInvalid, len(ibf))
 	}
 
+	p := ProgramData{Sequence: seq, Blocks: numblk}
+	copy(p.Data[:], ibf)
+	buf, err = p.MarshalBinary()
+
+	Packetdebug(buf, debug)
+	return buf, err
+}
+
+// Log a packet in the debug format, dec or hex.
+// Report whether debug asks for the packet output, dec or hex.
+func Debugging(debug string) bool {
+	return strings.Contains(debug, "dec") || strings.Contains(debug, "hex")
+}
+
+func Packetdebug(buf []byte, debug string) {
 	if strings.Contains(debug, "dec") {
 		log.Printf(" %d\n", buf)
 	} else if strings.Contains(debug, "hex") {
 		log.Printf(" %x\n", buf)
-	} else {
-		//log.Printf(" \n")
 	}
-	return buf, err
 }
 
+// Default time to listen for Discovery replies.
+const Discoverwindow time.Duration = 2 * time.Second
+
 // Send the Discovery packet to an interface.
 func Discover(addrStr string, bcastStr string, debug string) (strs []Hpsdrboard, er error) {
-	var b []byte
-	var str Hpsdrboard
-	str.Mac = make([]byte, 6, 6)
+	return Discoverwait(addrStr, bcastStr, Discoverwindow, debug)
+}
+
+// Send the Discovery packet to an interface and collect every reply
+// that arrives within the listen window, one entry per board MAC.
+func Discoverwait(addrStr string, bcastStr string, wait time.Duration, debug string) (strs []Hpsdrboard, er error) {
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	return DiscoverContext(ctx, addrStr, bcastStr, debug)
+}
 
-	log.Printf("          Discover: %s -> %s", addrStr, bcastStr)
+// Port the boards listen on for programming packets
+const Boardport string = "1024"
 
-	b, er1 := Makepacket("discover", 0, debug)
+// Split a comma separated list of board or broadcast addresses, such as
+// "10.1.2.3, 10.1.2.4:1024", adding Boardport where the port is missing.
+func Targetaddrs(targets string) (addrs []string) {
+	for _, t := range strings.Split(targets, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(t); err != nil {
+			t = net.JoinHostPort(t, Boardport)
+		}
+		addrs = append(addrs, t)
+	}
+	return addrs
+}
+
+// Send the Discovery packet and listen until the context deadline, or for
+// Discoverwindow when it has none.  bcastStr is a broadcast address or one
+// or more unicast board addresses, see Targetaddrs.  A TimeoutError is
+// returned only when no board answered at all.
+func DiscoverContext(ctx context.Context, addrStr string, bcastStr string, debug string) (strs []Hpsdrboard, er error) {
+	return DiscoverTargets(ctx, addrStr, Targetaddrs(bcastStr), debug)
+}
+
+// Send the Discovery packet to every target, broadcast or unicast, from one
+// socket and collect the replies, one entry per board MAC.  Unicast targets
+// reach boards behind a router where broadcasts do not.
+func DiscoverTargets(ctx context.Context, addrStr string, targets []string, debug string) (strs []Hpsdrboard, er error) {
+	tgtStr := strings.Join(targets, ",")
+	log.Printf("          Discover: %s -> %s", addrStr, tgtStr)
+
+	if len(targets) == 0 {
+		return strs, errors.New("discover: no target address")
+	}
+
+	ctx, cancel := withdefault(ctx, Discoverwindow)
+	defer cancel()
+
+	b, er1 := DiscoveryRequest{}.MarshalBinary()
 	if er1 != nil {
 		log.Println("Error After Makepacket", er1)
+		return strs, er1
 	}
-	//log.Println("After Makepacket", b)
+	Packetdebug(b, debug)
 
 	l, err := Commlink(addrStr)
-
-	//log.Println("After Commlink", b)
-	n, err := Commpacketsend(l, bcastStr, b)
 	if err != nil {
-		log.Println("Commpacketsend", n, err)
+		return strs, err
 	}
+	defer l.Close()
 
-	//log.Println("After Commpacketsend", b)
-	n, ad, c, err := Commpacketreceive(l)
-	if err != nil {
-		log.Println("Commpacketreceive", n, ad, err)
+	for _, t := range targets {
+		n, err := CommpacketsendContext(ctx, l, t, b)
+		if err != nil {
+			log.Println("Commpacketsend", n, err)
+			return strs, err
+		}
 	}
 
-	if strings.Contains(debug, "dec") {
-		log.Printf("     Received data: %v bytes from %v   %+v\n", n, ad, c)
-	} else if strings.Contains(debug, "hex") {
-		log.Printf("     Received data: %v bytes from %v   %x\n", n, ad, c)
-	} else {
-		log.Printf("     Received data: %v bytes from %v\n", n, ad)
-	}
-
-	str.Mac[0] = c[5]
-	str.Mac[1] = c[6]
-	str.Mac[2] = c[7]
-	str.Mac[3] = c[8]
-	str.Mac[4] = c[9]
-	str.Mac[5] = c[10]
-
-	str.Macaddress = fmt.Sprintf("%x:%x:%x:%x:%x:%x", c[5], c[6], c[7], c[8], c[9], c[10])
-	str.Pcaddress = addrStr
-
-	if c[4] == 2 {
-		str.Status = "not running"
-	} else if c[4] == 3 {
-		str.Status = "running"
-	}
-
-	if c[11] == 0 {
-		str.Board = "ATLAS"
-	} else if c[11] == 1 {
-		str.Board = "HERMES"
-	} else if c[11] == 2 {
-		str.Board = "HERMES"
-	} else if c[11] == 3 {
-		str.Board = "ANGELIA"
-	} else if c[11] == 4 {
-		str.Board = "ORION"
-	} else if c[11] == 4 {
-		str.Board = "ANAN-10E"
-	} else if c[11] == 6 {
-		str.Board = "HERMES-LITE"
-	} else {
-		str.Board = "Unknown"
-	}
+	seen := make(map[string]bool)
+	for {
+		//log.Println("After Commpacketsend", b)
+		n, ad, c, err := CommpacketreceiveContext(ctx, l)
+		if err != nil {
+			if _, ok := err.(*TimeoutError); ok {
+				if len(strs) == 0 {
+					return strs, &TimeoutError{Op: "discover", Addr: tgtStr, Wait: err.(*TimeoutError).Wait}
+				}
+				break
+			}
+			log.Println("Commpacketreceive", n, ad, err)
+			return strs, err
+		}
 
-	str.Protocol = fmt.Sprintf("%d.%d", c[12]/10, c[12]%10)
-	str.Firmware = fmt.Sprintf("%d.%d", c[13]/10, c[13]%10)
-	str.Atlas.Mercury1 = fmt.Sprintf("%d.%d", c[14]/10, c[14]%10)
-	str.Atlas.Mercury2 = fmt.Sprintf("%d.%d", c[15]/10, c[15]%10)
-	str.Atlas.Mercury3 = fmt.Sprintf("%d.%d", c[16]/10, c[16]%10)
-	str.Atlas.Mercury4 = fmt.Sprintf("%d.%d", c[17]/10, c[17]%10)
-	str.Atlas.Penelope = fmt.Sprintf("%d.%d", c[18]/10, c[18]%10)
-	str.Atlas.Metis = fmt.Sprintf("%d.%d", c[19]/10, c[19]%10)
+		if strings.Contains(debug, "dec") {
+			log.Printf("     Received data: %v bytes from %v   %+v\n", n, ad, c)
+		} else if strings.Contains(debug, "hex") {
+			log.Printf("     Received data: %v bytes from %v   %x\n", n, ad, c)
+		} else {
+			log.Printf("     Received data: %v bytes from %v\n", n, ad)
+		}
 
-	str.Receivers = int(c[20])
-	if c[21] == 0 {
-		str.Freqinput = "Frequency"
-	} else {
-		str.Freqinput = "Phase_word"
+		// only discovery replies, not running (2) or running (3)
+		var reply DiscoveryReply
+		if err := reply.UnmarshalBinary(c[:n]); err != nil {
+			log.Println("Discovery reply", err)
+			continue
+		}
+
+		str := reply.Hpsdrboard(ad, addrStr)
+		if seen[str.Macaddress] {
+			continue
+		}
+		seen[str.Macaddress] = true
+		strs = append(strs, str)
 	}
 
-	log.Printf("%b", c[22])
-	if c[22] == 0 {
-		str.Iqdata = "Big-Endian IQ in 3 byte format"
-	}else if c[22] == 1 {
-		str.Iqdata = "Little-Endian"
-	}else if c[22] == 2 {
-		str.Iqdata = "3 Byte format"
-	}else if c[22] == 3 {
-		str.Iqdata = "1 Float format"
-	}else if c[22] == 4 {
-		str.Iqdata = "1 Double format"
+	return strs, nil
+}
+
+// Find the board with the given MAC address in a Discovery result.
+func Findboard(strs []Hpsdrboard, mac string) (str Hpsdrboard, er error) {
+	for i := range strs {
+		if strings.EqualFold(strs[i].Macaddress, mac) {
+			return strs[i], nil
+		}
 	}
+	return str, &BoardError{Macaddress: mac}
+}
 
-	str.Baddress = ad.String()
-	strs = append(strs, str)
+// Address of a Set IP packet returning the board to DHCP
+var Dhcpaddress net.IP = net.IPv4bcast
 
-	l.Close()
+// Parse a new board address, "dhcp" gives Dhcpaddress.  The boards only
+// take IPv4 addresses.
+func Parseboardip(s string) (net.IP, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "dhcp") {
+		return Dhcpaddress, nil
+	}
+	ip := net.ParseIP(s)
+	if (ip == nil) || (ip.To4() == nil) {
+		return nil, &AddressError{Address: s, Reason: "not an IPv4 address"}
+	}
+	return ip.To4(), nil
+}
 
-	return strs, nil
+// Subnet of the interface address addrStr, nil when it is not the address
+// of an interface, such as 0.0.0.0.
+func Ipv4subnet(addrStr string) *net.IPNet {
+	host, _, err := net.SplitHostPort(addrStr)
+	if err != nil {
+		host = addrStr
+	}
+	for _, intf := range Interfaces() {
+		for _, ad := range intf.Ipv4addrs {
+			if ad.Ipv4 != host {
+				continue
+			}
+			if _, n, err := net.ParseCIDR(ad.Network); err == nil {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+// Check that ip can be given to a board reached from the interface address
+// addrStr.  Multicast, loopback and unspecified addresses are refused, and
+// so are the network and broadcast addresses of the interface subnet and
+// the address of the interface itself.  An address outside the subnet is
+// allowed, the board may be moved to another network, but a warning is
+// returned.  Dhcpaddress is always accepted.
+func Checkip(ip net.IP, addrStr string) (warning string, er error) {
+	ip4 := ip.To4()
+	switch {
+	case ip4 == nil:
+		return "", &AddressError{Address: ip.String(), Reason: "not an IPv4 address"}
+	case ip4.Equal(Dhcpaddress):
+		return "", nil
+	case ip4.IsUnspecified():
+		return "", &AddressError{Address: ip4.String(), Reason: "not a board address, use 255.255.255.255 for DHCP"}
+	case ip4.IsLoopback():
+		return "", &AddressError{Address: ip4.String(), Reason: "loopback address"}
+	case ip4.IsMulticast():
+		return "", &AddressError{Address: ip4.String(), Reason: "multicast address"}
+	}
 
+	n := Ipv4subnet(addrStr)
+	if n == nil {
+		return "", nil
+	}
+	host, _, _ := net.SplitHostPort(addrStr)
+	if ones, _ := n.Mask.Size(); ones < 31 {
+		if ip4.Equal(n.IP) {
+			return "", &AddressError{Address: ip4.String(), Reason: "network address of " + n.String()}
+		}
+		if ip4.Equal(Ipv4broadcast(n.IP, n.Mask)) {
+			return "", &AddressError{Address: ip4.String(), Reason: "broadcast address of " + n.String()}
+		}
+	}
+	if ip4.Equal(net.ParseIP(host)) {
+		return "", &AddressError{Address: ip4.String(), Reason: "address of this computer"}
+	}
+	if !n.Contains(ip4) {
+		return fmt.Sprintf("%s is outside the subnet %s of the interface, the board may not be reachable from it", ip4, n), nil
+	}
+	return "", nil
 }
 
 // Send the Set IP packet to an interface.
-func Setip(addrStr string, bcastStr string, str Hpsdrboard, nadr string, debug string) (msg SetIPmessage, er error) {
-	var b []byte
-	log.Printf("       Set IP sent: %s -> %s\n", addrStr, bcastStr)
+func Setip(addrStr string, bcastStr string, str Hpsdrboard, ip net.IP, debug string) (msg SetIPmessage, er error) {
+	return SetipContext(context.Background(), addrStr, bcastStr, str, ip, debug)
+}
 
-	b, er1 := Makepacket("setip", 0, debug)
-	if er1 != nil {
-		log.Println("Error After Makepacket", er1)
-	}
+// Send the Set IP packet after Checkip, then rediscover until the board
+// answers at its new address, or at any address for Dhcpaddress.  Bounded
+// by the context or Setiptimeout.
+func SetipContext(ctx context.Context, addrStr string, bcastStr string, str Hpsdrboard, ip net.IP, debug string) (msg SetIPmessage, er error) {
+	ctx, cancel := withdefault(ctx, Setiptimeout)
+	defer cancel()
+
+	log.Printf("       Set IP sent: %s -> %s\n", addrStr, bcastStr)
 
-	msg.Newaddress = nadr
 	msg.Oldaddress = str.Baddress
 	msg.Macaddress = str.Macaddress
+	msg.Newaddress = ip.String()
+	if len(str.Mac) != 6 {
+		return msg, &BoardError{Macaddress: str.Macaddress, Addr: bcastStr}
+	}
+
+	warning, err := Checkip(ip, addrStr)
+	if err != nil {
+		msg.Message = err.Error()
+		return msg, err
+	}
+	if warning != "" {
+		log.Printf("    Warning: %s\n", warning)
+		msg.Warning = warning
+	}
+	dhcp := ip.To4().Equal(Dhcpaddress)
 	msg.Message = "Setting new IP address"
+	if dhcp {
+		msg.Newaddress = "dhcp"
+		msg.Message = "Returning to DHCP"
+	}
 
-	// insert MAC address
-	b[5] = str.Mac[0]
-	b[6] = str.Mac[1]
-	b[7] = str.Mac[2]
-	b[8] = str.Mac[3]
-	b[9] = str.Mac[4]
-	b[10] = str.Mac[5]
-
-	// insert new address
-	newadr := strings.Split(nadr, ".")
-	s, _ := strconv.ParseInt(newadr[0], 10, 32)
-	b[11] = byte(s)
-	s, _ = strconv.ParseInt(newadr[1], 10, 32)
-	b[12] = byte(s)
-	s, _ = strconv.ParseInt(newadr[2], 10, 32)
-	b[13] = byte(s)
-	s, _ = strconv.ParseInt(newadr[3], 10, 32)
-	b[14] = byte(s)
+	b, er1 := SetIPRequest{MAC: str.Mac, IP: ip}.MarshalBinary()
+	if er1 != nil {
+		log.Println("Error After Makepacket", er1)
+		return msg, er1
+	}
+	Packetdebug(b, debug)
 
 	l, err := Commlink(addrStr)
-
-	n, err := Commpacketsend(l, bcastStr, b)
 	if err != nil {
-		log.Println("Commpacketsend", n, err)
+		return msg, err
 	}
+	defer l.Close()
 
-	//n, ad, c, err := Commpacketreceive(l)
-	//if err != nil {
-	//	log.Println("Commpacketreceive", n, ad, err)
-	//}
-
-	//if strings.Contains(debug, "dec") {
-	//	log.Printf("     Received data: %v bytes from %v   %+v\n", n, ad, c)
-	//} else if strings.Contains(debug, "hex") {
-	//	log.Printf("     Received data: %v bytes from %v   %x\n", n, ad, c)
-	//} else {
-	//	log.Printf("     Received data: %v bytes from %v\n", n, ad)
-	//}
-
-	l.Close()
+	for _, t := range Targetaddrs(bcastStr) {
+		n, err := CommpacketsendContext(ctx, l, t, b)
+		if err != nil {
+			log.Println("Commpacketsend", n, err)
+			return msg, err
+		}
+	}
 
-	return msg, nil
+	// the board may no longer answer where it was found, ask at the new
+	// address as well
+	targets := Targetaddrs(bcastStr)
+	if !dhcp {
+		targets = append(targets, net.JoinHostPort(ip.String(), Boardport))
+	}
+	// a DHCP board may still answer at the old address until it
+	// reboots, that is only confirmation once it has gone away
+	oldhost, _, _ := net.SplitHostPort(str.Baddress)
+	gone := false
+	start := time.Now()
+	for {
+		wctx, wcancel := context.WithTimeout(ctx, Packettimeout)
+		strs, err := DiscoverTargets(wctx, addrStr, targets, debug)
+		wcancel()
+		if (err != nil) && !errors.Is(err, ErrTimeout) {
+			return msg, err
+		}
+		nbrd, err := Findboard(strs, str.Macaddress)
+		if err != nil {
+			gone = true
+		} else {
+			host, _, _ := net.SplitHostPort(nbrd.Baddress)
+			moved := (host != oldhost) || gone
+			if (dhcp && moved) || (!dhcp && ip.Equal(net.ParseIP(host))) {
+				log.Printf("    Set IP confirmed: (%s) answers at %s\n", nbrd.Macaddress, nbrd.Baddress)
+				msg.Newaddress = host
+				msg.Message = "Board answers at the new IP address"
+				if dhcp {
+					msg.Message = "Board answers at its DHCP address"
+				}
+				msg.Board = &nbrd
+				return msg, nil
+			}
+		}
+		if ctx.Err() != nil {
+			er = ctx.Err()
+			if errors.Is(er, context.DeadlineExceeded) {
+				er = &TimeoutError{Op: "set IP", Addr: msg.Newaddress, Wait: time.Since(start)}
+			}
+			msg.Message = er.Error()
+			return msg, er
+		}
+	}
 }
 
 // Send the Erase packet to an interface.
 func Erase(addrStr string, str Hpsdrboard, debug string) (er error) {
+	return EraseContext(context.Background(), addrStr, str, debug)
+}
+
+// Send the Erase packet and wait for the started and finished replies,
+// bounded by the context or Erasetimeout.
+func EraseContext(ctx context.Context, addrStr string, str Hpsdrboard, debug string) (er error) {
+	return EraseProgress(ctx, addrStr, str, debug, nil)
+}
+
+// EraseContext reporting erase started, erase finished or failed to obs.
+func EraseProgress(ctx context.Context, addrStr string, str Hpsdrboard, debug string, obs Observer) (er error) {
 	var b []byte
 	log.Printf("             Erase: %s -> %s\n", addrStr, str.Baddress)
 
-	b, er1 := Makepacket("erase", 0, debug)
+	ctx, cancel := withdefault(ctx, Erasetimeout)
+	defer cancel()
+	start := time.Now()
+	defer func() {
+		if er != nil {
+			obs.send(Progress{Kind: Failed, Op: "erase", Macaddress: str.Macaddress, Err: er}, start)
+		}
+	}()
+
+	b, er1 := EraseRequest{}.MarshalBinary()
 	if er1 != nil {
 		log.Println("Error After Makepacket", er1)
+		return er1
 	}
+	Packetdebug(b, debug)
 
-	log.Printf("             Erase:  After Makepacket\n")
+	if Debugging(debug) {
+		log.Printf("             Erase:  After Makepacket\n")
+	}
 	l, err := Commlink(addrStr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
 
-	log.Printf("             Erase: After Comlink\n")
-	_, err = Commpacketsend(l, str.Baddress, b)
+	if Debugging(debug) {
+		log.Printf("             Erase: After Comlink\n")
+	}
+	_, err = CommpacketsendContext(ctx, l, str.Baddress, b)
 	if err != nil {
 		log.Println("Commpacketsend", err)
+		return err
 	}
 
-	log.Printf("             Erase: After Commpacket %+v\n", b)
-	for i := 0; i < 2; i++ {
-		n, ad, c, err := Commpacketreceive(l)
+	if Debugging(debug) {
+		log.Printf("             Erase: After Commpacket %+v\n", b)
+	}
+	for i := 0; i < 2; {
+		n, ad, c, err := CommpacketreceiveContext(ctx, l)
 		if err != nil {
 			log.Println("Commpacketreceive", err)
+			if _, ok := err.(*TimeoutError); ok {
+				return &TimeoutError{Op: "erase", Addr: str.Baddress, Wait: time.Since(start)}
+			}
+			return err
 		}
 
-		log.Printf("reply %d %d::%v::%+v\n", i+1, n, ad, c)
+		if Debugging(debug) {
+			log.Printf("reply %d %d::%v::%+v\n", i+1, n, ad, c)
+		}
 
-		if (c[4] == 3) && (binary.BigEndian.Uint32(c[0:4]) == uint32(0)) {
+		var ack EraseAck
+		if ack.UnmarshalBinary(c[:n]) == nil && ack.Sequence == 0 {
 			if strings.Contains(debug, "dec") {
 				log.Printf("     Received data: %v bytes from %v   %+v\n", n, ad, c)
 			} else if strings.Contains(debug, "hex") {
@@ -552,12 +851,16 @@ func Erase(addrStr string, str Hpsdrboard, debug string) (er error) {
 					log.Printf("    Erase Finished: %v bytes from %v\n", n, ad)
 				}
 			}
+			if i == 0 {
+				obs.send(Progress{Kind: Erasestarted, Op: "erase", Macaddress: str.Macaddress}, start)
+			} else {
+				obs.send(Progress{Kind: Erasefinished, Op: "erase", Macaddress: str.Macaddress}, start)
+			}
+			i++
 		}
 	}
 	log.Printf("    Erase complete: \n")
 
-	l.Close()
-
 	return nil
 }
 
@@ -565,18 +868,14 @@ func Erase(addrStr string, str Hpsdrboard, debug string) (er error) {
 func Erasenew(str Hpsdrboard, debug string) (er error) {
 	var emsg chan int
 	var idx int
-	var lapstm chan Erasemessage
-	var lt Erasemessage
-	lapstm = make(chan Erasemessage)
+	var done chan error
 	emsg = make(chan int)
+	done = make(chan error, 1)
 	log.Printf("             Erase: %s -> %s\n", str.Pcaddress, str.Baddress)
 
-	er1 := Erasefunc(str, emsg, debug)
-	if er1 != nil {
-		log.Println("Error After Errorfunc", er1)
-	}
-
-	//go Lapstime(&lapstm)
+	go func() {
+		done <- Erasefunc(str, emsg, debug)
+	}()
 
 	for {
 		select {
@@ -585,22 +884,16 @@ func Erasenew(str Hpsdrboard, debug string) (er error) {
 				log.Println(" Erase started. ")
 			} else {
 				log.Println(" Erase finished. ")
-				break
 			}
-		case lt = <-lapstm:
-			ss := fmt.Sprintf("%d Seconds. ", lt.Ltime)
-			log.Println(ss)
-			//if lt.Done {
-			log.Println(" Erase timed out.")
-			err := errors.New("Erase timed out")
-			return err
-			//}
-			//default:
+		case er = <-done:
+			if er != nil {
+				log.Println(" Erase failed. ", er)
+				return er
+			}
+			log.Printf("    Erase complete: \n")
+			return nil
 		}
 	}
-	log.Printf("    Erase complete: \n")
-
-	return nil
 }
 
 // Send and receive the Erase Packets.
@@ -609,29 +902,44 @@ func Erasefunc(str Hpsdrboard, emsg chan int, debug string) (er error) {
 	b, er1 := Makepacket("erase", 0, debug)
 	if er1 != nil {
 		log.Println("Error After Makepacket", er1)
+		return er1
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), Erasetimeout)
+	defer cancel()
+
 	l, err := Commlink(str.Pcaddress)
+	if err != nil {
+		return err
+	}
 
-	_, err = Commpacketsend(l, str.Baddress, b)
+	_, err = CommpacketsendContext(ctx, l, str.Baddress, b)
 	if err != nil {
 		log.Println("Commpacketsend", err)
+		l.Close()
+		return err
 	}
 
 	var i int
 	for {
-		n, ad, c, err := Commpacketreceive(l)
+		n, ad, c, err := CommpacketreceiveContext(ctx, l)
 		if err != nil {
 			log.Println("Commpacketreceive", err)
+			l.Close()
+			return err
 		}
 
-		log.Printf("reply %d %d::%v::%+v\n", i+1, n, ad, c)
+		if Debugging(debug) {
+			log.Printf("reply %d %d::%v::%+v\n", i+1, n, ad, c)
+		}
 
-		if c[4] == 3 && binary.BigEndian.Uint32(c[0:4]) == uint32(0) {
+		var ack EraseAck
+		if ack.UnmarshalBinary(c[:n]) == nil && ack.Sequence == 0 {
 			emsg <- i
 			if i > 0 {
 				break
 			}
+			i++
 		}
 	}
 
@@ -660,18 +968,44 @@ func Lapstime(lps *Erasemessage) {
 
 // Send the Program packet to an interface.
 func Program(addrStr string, str Hpsdrboard, input string, debug string) (er error) {
+	return ProgramContext(context.Background(), addrStr, str, input, debug)
+}
+
+// Send the Program packets, waiting at most Packettimeout for each block
+// to be acknowledged and resending it up to Blockretries times, within a
+// Retrybudget for the run.  Canceling the context abandons the run.
+func ProgramContext(ctx context.Context, addrStr string, str Hpsdrboard, input string, debug string) (er error) {
+	return ProgramProgress(ctx, addrStr, str, input, false, debug, nil)
+}
+
+// ProgramContext reporting every block sent, acked or resent, and the end
+// of the run, to obs.  force programs a file whose manifest does not match.
+func ProgramProgress(ctx context.Context, addrStr string, str Hpsdrboard, input string, force bool, debug string, obs Observer) (er error) {
 	log.Printf("Program: %s -> %s\n", addrStr, str.Baddress)
+	start := time.Now()
+	var packets uint32
+	ipk := uint32(0)
+	budget := Retrybudget
+	defer func() {
+		p := Progress{Kind: Programdone, Op: "program", Macaddress: str.Macaddress, Block: ipk, Blocks: packets, Retries: Retrybudget - budget, Bytes: int64(ipk) * int64(Blocklen)}
+		if er != nil {
+			p.Kind = Failed
+			p.Err = er
+		}
+		obs.send(p, start)
+	}()
 
 	// Open the RBF file
 	f, err := os.Open(input)
 	if err != nil {
-		log.Fatal(err)
+		log.Println("Could not open the file", err)
+		return &FileError{Filename: input, Err: err}
 	}
 
 	defer func() {
 		err := f.Close()
 		if err != nil {
-			log.Fatal(err)
+			log.Println("Could not close the file", err)
 		}
 	}()
 
@@ -679,10 +1013,20 @@ func Program(addrStr string, str Hpsdrboard, input string, debug string) (er err
 	fi, err := f.Stat()
 	if err != nil {
 		log.Println("Could not open the file")
+		return &FileError{Filename: input, Err: err}
+	}
+
+	if _, err := Validaterbf(input, str); err != nil {
+		log.Println("RBF file rejected", err)
+		return err
+	}
+	if _, _, err := Checkmanifest(input, str); (err != nil) && !force {
+		log.Println("RBF file rejected", err)
+		return err
 	}
 
 	log.Println("      Programming the HPSDR Board")
-	packets := uint32(math.Ceil(float64(fi.Size()) / 256.0))
+	packets = uint32(math.Ceil(float64(fi.Size()) / 256.0))
 	log.Println("    Found rbf file:", input)
 	log.Println("     Size rbf file:", fi.Size())
 	log.Println("Size rbf in memory:", ((fi.Size()+255)/256)*256)
@@ -692,14 +1036,18 @@ func Program(addrStr string, str Hpsdrboard, input string, debug string) (er err
 	r := bufio.NewReader(f)
 
 	l, err := Commlink(addrStr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
 
 	buf := make([]byte, 256)
-	ipk := uint32(0)
 	for {
 		// read a chunk
 		n, err := r.Read(buf)
 		if err != nil && err != io.EOF {
-			log.Fatal(err)
+			log.Println("Could not read the file", err)
+			return &FileError{Filename: input, Err: err}
 		}
 
 		// No more data in file
@@ -719,42 +1067,90 @@ func Program(addrStr string, str Hpsdrboard, input string, debug string) (er err
 		b, er1 := Makepacketprogram(buf, ipk, packets, debug)
 		if er1 != nil {
 			log.Println("Error After Makepacketprogram", er1)
+			return er1
 		}
 
+		retries := 0
+		obs.send(Progress{Kind: Blocksent, Op: "program", Macaddress: str.Macaddress, Block: ipk, Blocks: packets, Retries: Retrybudget - budget, Bytes: int64(ipk) * int64(Blocklen)}, start)
 		for {
-			n, err := Commpacketsend(l, str.Baddress, b)
-			if err != nil {
-				log.Println("Commpacketsend", err)
+			err := programblock(ctx, l, str.Baddress, b, ipk, debug)
+			if err == nil {
+				obs.send(Progress{Kind: Blockacked, Op: "program", Macaddress: str.Macaddress, Block: ipk, Blocks: packets, Retries: Retrybudget - budget, Bytes: int64(ipk+1) * int64(Blocklen)}, start)
+				ipk++
+				break
+			}
+			if err == errComplete {
+				log.Printf("     Program complete: \n")
+				return nil
 			}
 
-			n, ad, c, err := Commpacketreceive(l)
-			if err != nil {
-				log.Println("Commpacketreceive", err)
+			// resend the block when the ack did not arrive in time
+			if _, ok := err.(*TimeoutError); ok && ctx.Err() == nil && retries < Blockretries && budget > 0 {
+				retries++
+				budget--
+				log.Printf("     Resending block %d, retry %d\n", ipk, retries)
+				obs.send(Progress{Kind: Blockretry, Op: "program", Macaddress: str.Macaddress, Block: ipk, Blocks: packets, Retries: Retrybudget - budget, Bytes: int64(ipk) * int64(Blocklen)}, start)
+				continue
 			}
+			log.Println("Program failed", err)
+			return &ProgramError{Addr: str.Baddress, Lastgood: int64(ipk) - 1, Blocks: packets, Retries: Retrybudget - budget, Err: err}
+		}
 
-			recnum := binary.BigEndian.Uint32(c[0:4])
-			sennum := ipk
-			if (c[4] == 4) && (binary.BigEndian.Uint32(c[0:4]) == ipk) {
-				ipk++
+	}
+	return nil
+}
+
+// The board replied to the current block with something other than an ack.
+var errComplete = errors.New("program complete")
+
+// Send one program block and wait for its ack.  Duplicate or stale acks
+// for earlier blocks are ignored while waiting.
+func programblock(ctx context.Context, l *net.UDPConn, baddr string, b []byte, ipk uint32, debug string) error {
+	bctx, cancel := context.WithTimeout(ctx, Packettimeout)
+	defer cancel()
+	start := time.Now()
+
+	_, err := CommpacketsendContext(bctx, l, baddr, b)
+	if err != nil {
+		log.Println("Commpacketsend", err)
+		return err
+	}
+
+	for {
+		n, ad, c, err := CommpacketreceiveContext(bctx, l)
+		if err != nil {
+			log.Println("Commpacketreceive", err)
+			if _, ok := err.(*TimeoutError); ok && ctx.Err() == nil {
+				return &TimeoutError{Op: fmt.Sprintf("program block %d", ipk), Addr: baddr, Wait: time.Since(start)}
+			}
+			return err
+		}
+
+		var ack ProgramAck
+		recnum := binary.BigEndian.Uint32(c[0:4])
+		if ack.UnmarshalBinary(c[:n]) == nil {
+			if ack.Sequence == ipk {
 				if strings.Contains(debug, "dec") {
 					log.Printf("     Received data: %v bytes from %v   %+v\n", n, ad, c)
 				} else if strings.Contains(debug, "hex") {
 					log.Printf("     Received data: %v bytes from %v   %x\n", n, ad, c)
 				} else {
-					log.Printf("     Received data: sent %d = rec %d, %v bytes from %v", sennum, recnum, n, ad)
+					log.Printf("     Received data: sent %d = rec %d, %v bytes from %v", ipk, recnum, n, ad)
 				}
-				break
-			} else if binary.BigEndian.Uint32(c[0:4]) == ipk {
-				log.Printf("     Program complete: \n")
-				l.Close()
 				return nil
-			} else {
-				outstr := fmt.Sprintf("     Received data: sent %d = rec %d, %v bytes from %v %+v\n", sennum, recnum, n, ad, c)
-				panic(outstr)
 			}
+			if ack.Sequence < ipk {
+				log.Printf("     Ignoring stale ack: sent %d, rec %d\n", ipk, ack.Sequence)
+				continue
+			}
+		} else if recnum == ipk {
+			return errComplete
 		}
-
+		if Debugging(debug) {
+			log.Printf("     Received data: sent %d = rec %d, %v bytes from %v %+v\n", ipk, recnum, n, ad, c)
+		} else {
+			log.Printf("     Received data: sent %d = rec %d, %v bytes from %v\n", ipk, recnum, n, ad)
+		}
+		return &SequenceError{Addr: baddr, Sent: ipk, Received: recnum, Reply: c[4]}
 	}
-	l.Close()
-	return nil
 }
@@ -0,0 +1,79 @@
+// Progress of the Erase and Program operations
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"time"
+)
+
+// Kind of progress event
+type Progresskind int
+
+const (
+	Erasestarted  Progresskind = iota // the board acked the erase request
+	Erasefinished                     // the board reported the flash erased
+	Blocksent                         // a program block was sent
+	Blockacked                        // a program block was acknowledged
+	Blockretry                        // a program block was resent
+	Programdone                       // every block was acknowledged
+	Failed                            // the erase or program stopped, see Err
+)
+
+var progressnames = []string{"erase started", "erase finished", "block sent", "block acked", "block retry", "program done", "failed"}
+
+func (k Progresskind) String() string {
+	if (k >= 0) && (int(k) < len(progressnames)) {
+		return progressnames[k]
+	}
+	return "unknown"
+}
+
+// MarshalText writes the kind by name in JSON.
+func (k Progresskind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// One progress event of an Erase or Program run.  Block, Blocks, Bytes,
+// Rate and Eta are only set by Program.
+type Progress struct {
+	Kind       Progresskind  `json:"kind"`
+	Op         string        `json:"op"` // erase or program
+	Macaddress string        `json:"macaddress"`
+	Block      uint32        `json:"block"`  // block sent, acked or resent
+	Blocks     uint32        `json:"blocks"` // blocks in the file
+	Retries    int           `json:"retries"`
+	Bytes      int64         `json:"bytes"` // acknowledged so far
+	Elapsed    time.Duration `json:"-"`
+	Eta        time.Duration `json:"-"`
+	Seconds    float64       `json:"seconds"`    // Elapsed
+	Etaseconds float64       `json:"etaseconds"` // Eta
+	Rate       float64       `json:"rate"`       // bytes per second
+	Err        error         `json:"-"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Observer receives the progress events of a run, on the goroutine
+// running it, so it should return quickly.  A nil Observer is allowed.
+type Observer func(p Progress)
+
+// Fill the timing fields and send the event to the observer.
+func (obs Observer) send(p Progress, start time.Time) {
+	if obs == nil {
+		return
+	}
+	p.Elapsed = time.Since(start)
+	if (p.Op == "program") && (p.Bytes > 0) && (p.Elapsed > 0) {
+		p.Rate = float64(p.Bytes) / p.Elapsed.Seconds()
+		left := int64(p.Blocks)*int64(Blocklen) - p.Bytes
+		if left > 0 {
+			p.Eta = time.Duration(float64(left) / p.Rate * float64(time.Second))
+		}
+	}
+	p.Seconds = p.Elapsed.Seconds()
+	p.Etaseconds = p.Eta.Seconds()
+	if p.Err != nil {
+		p.Error = p.Err.Error()
+	}
+	obs(p)
+}
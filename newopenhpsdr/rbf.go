@@ -0,0 +1,94 @@
+// Check an RBF image before the flash is erased
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Limits of a usable RBF file
+const (
+	Rbfminsize  int64 = 64 << 10 // smaller files are truncated
+	Rbfpreamble int   = 8        // 0xFF bytes an Altera raw binary file starts with
+)
+
+// What Validaterbf found in an RBF file.
+type Rbfinfo struct {
+	Filename  string `json:"filename"`
+	Size      int64  `json:"size"`
+	Blocks    uint32 `json:"blocks"`
+	Preamble  int    `json:"preamble"`  // leading 0xFF bytes
+	Flashsize int64  `json:"flashsize"` // of the target board, 0 when unknown
+}
+
+// Check that an RBF file can be written to the board, before Erase is
+// sent: the file is not empty or truncated, fits in the flash of the board
+// and starts with the 0xFF padding of an Altera raw binary file.  A
+// board that is not in the registry skips the flash size check.
+func Validaterbf(filename string, str Hpsdrboard) (info Rbfinfo, er error) {
+	info.Filename = filename
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return info, &FileError{Filename: filename, Err: err}
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return info, &FileError{Filename: filename, Err: err}
+	}
+	if !fi.Mode().IsRegular() {
+		return info, &FileError{Filename: filename, Err: errors.New("not a regular file")}
+	}
+	info.Size = fi.Size()
+	info.Blocks = uint32((info.Size + int64(Blocklen) - 1) / int64(Blocklen))
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return info, &FileError{Filename: filename, Err: err}
+	}
+	head = head[:n]
+
+	if b, ok := Boardbyname(str.Board); ok {
+		info.Flashsize = b.Flashsize
+	}
+
+	if err := Checkrbf(head, info.Size, info.Flashsize); err != nil {
+		return info, &FileError{Filename: filename, Err: err}
+	}
+	info.Preamble = len(head) - len(bytes.TrimLeft(head, "\xff"))
+
+	log.Printf("         RBF check: %s %d bytes, %d blocks, flash %d bytes\n", filename, info.Size, info.Blocks, info.Flashsize)
+	return info, nil
+}
+
+// Check the first bytes and the size of an RBF image, flash 0 skips the
+// flash size check.
+func Checkrbf(head []byte, size int64, flash int64) error {
+	switch {
+	case size == 0:
+		return errors.New("file is empty")
+	case bytes.HasPrefix(head, []byte("PK")):
+		return errors.New("file is a zip archive, extract the RBF file first")
+	case bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")):
+		return errors.New("file is an HTML or XML page, not an RBF image")
+	case size < Rbfminsize:
+		return fmt.Errorf("file is %d bytes, truncated below %d", size, Rbfminsize)
+	case (flash > 0) && (size > flash):
+		return fmt.Errorf("file is %d bytes, larger than the %d byte flash", size, flash)
+	}
+
+	pre := len(head) - len(bytes.TrimLeft(head, "\xff"))
+	if pre < Rbfpreamble {
+		return fmt.Errorf("file starts with %d 0xFF bytes, want %d, not an Altera raw binary file", pre, Rbfpreamble)
+	}
+	return nil
+}
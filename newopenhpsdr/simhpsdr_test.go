@@ -0,0 +1,383 @@
+// Tests of the board operations against the simulated board of simhpsdr
+// GPL2
+//
+package newopenhpsdr_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/simhpsdr"
+)
+
+// Local address the operations send from
+const local string = "127.0.0.1:0"
+
+func init() {
+	// the simulator answers at once, a lost reply need not cost a second
+	newopenhpsdr.Packettimeout = 50 * time.Millisecond
+}
+
+// Start a simulated board on a free loopback port.
+func startboard(t *testing.T, addr string, cfg simhpsdr.Config) *simhpsdr.Board {
+	t.Helper()
+	if cfg.Erasetime == 0 {
+		cfg.Erasetime = 50 * time.Millisecond
+	}
+	b, err := simhpsdr.New(addr, cfg)
+	if err != nil {
+		t.Fatalf("simhpsdr.New: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+// Discover one board at its address.
+func findboard(t *testing.T, b *simhpsdr.Board) newopenhpsdr.Hpsdrboard {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	strs, err := newopenhpsdr.DiscoverContext(ctx, local, b.Addr().String(), "none")
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(strs) != 1 {
+		t.Fatalf("Discover found %d boards, want 1", len(strs))
+	}
+	return strs[0]
+}
+
+// Write an RBF image of size bytes and return its name and content.
+func writerbf(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+	img := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(img)
+	for i := 0; i < 32; i++ {
+		img[i] = 0xff
+	}
+	name := filepath.Join(t.TempDir(), "Hermes_v10.4.rbf")
+	if err := os.WriteFile(name, img, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name, img
+}
+
+// Faults on the program acks only, each block at most once so a resend
+// gets through.
+func programfaults(fault simhpsdr.Fault, every uint32) simhpsdr.Faultfunc {
+	var mu sync.Mutex
+	done := make(map[uint32]bool)
+	return func(cmd byte, seq uint32) simhpsdr.Fault {
+		if (cmd != newopenhpsdr.Cmdprogram) || (seq%every != 0) {
+			return simhpsdr.Deliver
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if (fault != simhpsdr.Duplicate) && done[seq] {
+			return simhpsdr.Deliver
+		}
+		done[seq] = true
+		return fault
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	a := startboard(t, "127.0.0.1:0", simhpsdr.Config{BoardID: 1, Firmware: 103})
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{BoardID: 5, Firmware: 21,
+		MAC:   net.HardwareAddr{0x00, 0x1c, 0xc0, 0xa2, 0x13, 0x02},
+		Fault: func(byte, uint32) simhpsdr.Fault { return simhpsdr.Duplicate }})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	strs, err := newopenhpsdr.DiscoverContext(ctx, local, a.Addr().String()+","+b.Addr().String(), "none")
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(strs) != 2 {
+		t.Fatalf("Discover found %d boards, want 2 without duplicates: %+v", len(strs), strs)
+	}
+	for _, want := range []*simhpsdr.Board{a, b} {
+		found := false
+		for _, s := range strs {
+			found = found || (s.Baddress == want.Addr().String())
+		}
+		if !found {
+			t.Errorf("no board at %v in %+v", want.Addr(), strs)
+		}
+	}
+}
+
+func TestDiscoverNone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := newopenhpsdr.DiscoverContext(ctx, local, "127.0.0.1:9", "none")
+	if !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		t.Fatalf("Discover with no board: got %v, want ErrTimeout", err)
+	}
+}
+
+func TestSetipDhcp(t *testing.T) {
+	// a board whose DHCP server hands out 127.0.0.2
+	if l, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 2)}); err != nil {
+		t.Skipf("127.0.0.2 cannot be bound here: %v", err)
+	} else {
+		l.Close()
+	}
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{Dhcpip: net.IPv4(127, 0, 0, 2)})
+	brd := findboard(t, b)
+	port := b.Addr().Port
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	targets := net.JoinHostPort("127.0.0.1", strconv.Itoa(port)) + "," + net.JoinHostPort("127.0.0.2", strconv.Itoa(port))
+	msg, err := newopenhpsdr.SetipContext(ctx, local, targets, brd, newopenhpsdr.Dhcpaddress, "none")
+	if err != nil {
+		t.Fatalf("Setip: %v", err)
+	}
+	if (msg.Board == nil) || (msg.Board.Baddress != b.Addr().String()) || (msg.Newaddress != "127.0.0.2") {
+		t.Errorf("Setip confirmed %+v, want the board at %v", msg, b.Addr())
+	}
+	if b.Stats().Setip != 1 {
+		t.Errorf("board got %d Set IP packets, want 1", b.Stats().Setip)
+	}
+}
+
+func TestSetipDhcpUnmoved(t *testing.T) {
+	// the board keeps answering where it was, which is no proof it took
+	// the DHCP request
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{})
+	brd := findboard(t, b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	msg, err := newopenhpsdr.SetipContext(ctx, local, brd.Baddress, brd, newopenhpsdr.Dhcpaddress, "none")
+	if !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		t.Fatalf("Setip: got %v, want ErrTimeout", err)
+	}
+	if msg.Board != nil {
+		t.Errorf("Setip confirmed the board at its old address %s", msg.Board.Baddress)
+	}
+}
+
+func TestParseboardip(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want net.IP
+	}{
+		{"192.168.1.30", net.IPv4(192, 168, 1, 30).To4()},
+		{" 10.0.0.2 ", net.IPv4(10, 0, 0, 2).To4()},
+		{"DHCP", newopenhpsdr.Dhcpaddress},
+		{"::ffff:192.168.1.30", net.IPv4(192, 168, 1, 30).To4()},
+		{"fd00::2", nil},
+		{"::1", nil},
+		{"192.168.1", nil},
+		{"", nil},
+		{"board", nil},
+	} {
+		ip, err := newopenhpsdr.Parseboardip(c.in)
+		if c.want == nil {
+			if !errors.Is(err, newopenhpsdr.ErrAddressInvalid) {
+				t.Errorf("Parseboardip(%q) = %v, %v, want ErrAddressInvalid", c.in, ip, err)
+			}
+			continue
+		}
+		if (err != nil) || !ip.Equal(c.want) || (len(ip) != len(c.want)) {
+			t.Errorf("Parseboardip(%q) = %v, %v, want %v", c.in, ip, err, c.want)
+		}
+	}
+}
+
+func TestSetipRefused(t *testing.T) {
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{})
+	brd := findboard(t, b)
+
+	for _, ip := range []string{"127.0.0.9", "224.0.0.5", "0.0.0.0", "fd00::2"} {
+		_, err := newopenhpsdr.SetipContext(context.Background(), local, brd.Baddress, brd, net.ParseIP(ip), "none")
+		if !errors.Is(err, newopenhpsdr.ErrAddressInvalid) {
+			t.Errorf("Setip %s: got %v, want ErrAddressInvalid", ip, err)
+		}
+	}
+	if b.Stats().Setip != 0 {
+		t.Errorf("board got %d Set IP packets, want none", b.Stats().Setip)
+	}
+}
+
+func TestSetipNotConfirmed(t *testing.T) {
+	// the simulator cannot bind 10.255.0.9, so it never answers there;
+	// loopback cannot send off the host, use the unspecified address
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{})
+	brd := findboard(t, b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err := newopenhpsdr.SetipContext(ctx, "0.0.0.0:0", brd.Baddress, brd, net.IPv4(10, 255, 0, 9), "none")
+	if !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		t.Fatalf("Setip: got %v, want ErrTimeout", err)
+	}
+	if b.Stats().Setip != 1 {
+		t.Errorf("board got %d Set IP packets, want 1", b.Stats().Setip)
+	}
+}
+
+func TestErase(t *testing.T) {
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{})
+	brd := findboard(t, b)
+
+	var kinds []newopenhpsdr.Progresskind
+	obs := func(p newopenhpsdr.Progress) { kinds = append(kinds, p.Kind) }
+	if err := newopenhpsdr.EraseProgress(context.Background(), local, brd, "none", obs); err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+	want := []newopenhpsdr.Progresskind{newopenhpsdr.Erasestarted, newopenhpsdr.Erasefinished}
+	if len(kinds) != len(want) || kinds[0] != want[0] || kinds[1] != want[1] {
+		t.Errorf("progress %v, want %v", kinds, want)
+	}
+}
+
+func TestEraseTimeout(t *testing.T) {
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{Erasetime: time.Hour})
+	brd := findboard(t, b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err := newopenhpsdr.EraseProgress(ctx, local, brd, "none", nil)
+	if !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		t.Fatalf("Erase of a board that never finishes: got %v, want ErrTimeout", err)
+	}
+}
+
+func TestProgram(t *testing.T) {
+	name, img := writerbf(t, 100032)
+	want := append(append([]byte(nil), img...), bytes.Repeat([]byte{0xff}, 256-len(img)%256)...)
+
+	for _, c := range []struct {
+		name    string
+		fault   simhpsdr.Faultfunc
+		retries bool
+	}{
+		{"clean", nil, false},
+		{"drop", programfaults(simhpsdr.Drop, 7), true},
+		{"duplicate", programfaults(simhpsdr.Duplicate, 3), false},
+		{"out of order", programfaults(simhpsdr.Reorder, 5), true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			b := startboard(t, "127.0.0.1:0", simhpsdr.Config{Fault: c.fault})
+			brd := findboard(t, b)
+
+			var last newopenhpsdr.Progress
+			obs := func(p newopenhpsdr.Progress) { last = p }
+			if err := newopenhpsdr.ProgramProgress(context.Background(), local, brd, name, false, "none", obs); err != nil {
+				t.Fatalf("Program: %v", err)
+			}
+			if last.Kind != newopenhpsdr.Programdone {
+				t.Errorf("last progress %v, want %v", last.Kind, newopenhpsdr.Programdone)
+			}
+			if c.retries != (last.Retries > 0) {
+				t.Errorf("%d retries", last.Retries)
+			}
+			if !bytes.Equal(b.Image(), want) {
+				t.Errorf("board image of %d bytes differs from the %d byte file", len(b.Image()), len(want))
+			}
+		})
+	}
+}
+
+func TestProgramLost(t *testing.T) {
+	name, _ := writerbf(t, 100032)
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{Fault: func(cmd byte, seq uint32) simhpsdr.Fault {
+		if (cmd == newopenhpsdr.Cmdprogram) && (seq == 10) {
+			return simhpsdr.Drop
+		}
+		return simhpsdr.Deliver
+	}})
+	brd := findboard(t, b)
+
+	err := newopenhpsdr.ProgramProgress(context.Background(), local, brd, name, false, "none", nil)
+	var pe *newopenhpsdr.ProgramError
+	if !errors.As(err, &pe) || !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		t.Fatalf("Program with block 10 never acked: got %v, want a ProgramError timing out", err)
+	}
+	if pe.Lastgood != 9 || pe.Retries != newopenhpsdr.Blockretries {
+		t.Errorf("stopped after block %d with %d retries, want 9 and %d", pe.Lastgood, pe.Retries, newopenhpsdr.Blockretries)
+	}
+}
+
+func TestProgramManifest(t *testing.T) {
+	name, _ := writerbf(t, 100032)
+	if err := os.WriteFile(name+".json", []byte(`{"board": "Angelia"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{})
+	brd := findboard(t, b)
+
+	err := newopenhpsdr.ProgramProgress(context.Background(), local, brd, name, false, "none", nil)
+	if !errors.Is(err, newopenhpsdr.ErrManifestMismatch) {
+		t.Fatalf("Program with an Angelia manifest: got %v, want ErrManifestMismatch", err)
+	}
+	if b.Stats().Program != 0 {
+		t.Fatalf("board got %d program blocks of a refused file", b.Stats().Program)
+	}
+	if err := newopenhpsdr.ProgramProgress(context.Background(), local, brd, name, true, "none", nil); err != nil {
+		t.Fatalf("forced Program: %v", err)
+	}
+}
+
+func TestProgramall(t *testing.T) {
+	good, _ := writerbf(t, 100032)
+	short := filepath.Join(t.TempDir(), "Hermes_v10.5.rbf")
+	if err := os.WriteFile(short, bytes.Repeat([]byte{0xff}, 1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := startboard(t, "127.0.0.1:0", simhpsdr.Config{})
+	b := startboard(t, "127.0.0.1:0", simhpsdr.Config{MAC: net.HardwareAddr{0x00, 0x1c, 0xc0, 0xa2, 0x13, 0x02}})
+	jobs := []newopenhpsdr.Batchjob{
+		{Addr: local, Board: findboard(t, a), Rbf: good, Erase: true},
+		{Addr: local, Board: findboard(t, b), Rbf: short, Erase: true},
+	}
+
+	sum := newopenhpsdr.Programall(context.Background(), jobs, 2, "none", nil)
+	if sum.Done != 1 || sum.Failed != 1 {
+		t.Fatalf("batch %d done %d failed, want 1 and 1: %+v", sum.Done, sum.Failed, sum.Results)
+	}
+	if sum.Results[0].State != "done" || sum.Results[1].State != "failed" {
+		t.Errorf("states %s and %s, want done and failed", sum.Results[0].State, sum.Results[1].State)
+	}
+	if !errors.Is(sum.Results[1].Err, newopenhpsdr.ErrFileInvalid) {
+		t.Errorf("short file: got %v, want ErrFileInvalid", sum.Results[1].Err)
+	}
+	if b.Stats().Erase != 0 {
+		t.Errorf("board of the short file got %d erase packets, want none", b.Stats().Erase)
+	}
+	if a.Stats().Erase != 1 {
+		t.Errorf("board of the good file got %d erase packets, want 1", a.Stats().Erase)
+	}
+}
+
+func TestSendContext(t *testing.T) {
+	l, err := newopenhpsdr.Commlink(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := newopenhpsdr.CommpacketsendContext(ctx, l, "127.0.0.1:9", make([]byte, 60)); !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		t.Errorf("send after the deadline: got %v, want ErrTimeout", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := newopenhpsdr.CommpacketsendContext(ctx, l, "127.0.0.1:9", make([]byte, 60)); !errors.Is(err, context.Canceled) {
+		t.Errorf("send canceled: got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,141 @@
+// Verify a board after programming
+// GPL2
+//
+package newopenhpsdr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Time callers usually give the board to reboot before Verify looks for
+// it, and the longest time to keep looking.
+var (
+	Rebootdelay   time.Duration = 5 * time.Second
+	Reboottimeout time.Duration = 60 * time.Second
+)
+
+// ErrVerifyFailed is returned when the board answers with other values
+// than expected.
+var ErrVerifyFailed = errors.New("verification failed")
+
+// Values a board should report after programming, empty fields are not
+// checked.
+type Expect struct {
+	Board    string `json:"board"`
+	Firmware string `json:"firmware"`
+	Protocol string `json:"protocol"`
+}
+
+// One field compared by Verify.
+type Verifycheck struct {
+	Field  string `json:"field"`
+	Want   string `json:"want"`
+	Got    string `json:"got"`
+	Passed bool   `json:"passed"`
+}
+
+// Result of a Verify run.
+type Verifyresult struct {
+	Macaddress string        `json:"macaddress"`
+	Found      bool          `json:"found"`
+	Board      Hpsdrboard    `json:"board"`
+	Expect     Expect        `json:"expect"`
+	Checks     []Verifycheck `json:"checks"`
+	Passed     bool          `json:"passed"`
+	Seconds    float64       `json:"seconds"`
+	Message    string        `json:"message"`
+}
+
+var rbfversion = regexp.MustCompile(`[vV](\d+)\.(\d+)`)
+
+// Guess the board and firmware version from an RBF file name such as
+// Angelia_Protocol2_v10.3.rbf.
+func Rbfexpect(filename string) (exp Expect) {
+	base := filepath.Base(filename)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if b, ok := Rbfboard(base); ok {
+		exp.Board = b.Name
+	} else if i := strings.IndexAny(base, "_-. "); i > 0 {
+		exp.Board = strings.ToUpper(base[:i])
+	}
+	if m := rbfversion.FindStringSubmatch(base); m != nil {
+		exp.Firmware = m[1] + "." + m[2]
+	}
+	return exp
+}
+
+// Wait for the board with the given MAC to reboot, rediscover it and
+// compare what it reports with the expected values.  The board is not
+// looked for during the first wait, such as Rebootdelay.
+func Verify(addrStr string, bcastStr string, mac string, exp Expect, wait time.Duration, debug string) (res Verifyresult, er error) {
+	return VerifyContext(context.Background(), addrStr, bcastStr, mac, exp, wait, debug)
+}
+
+// Verify bounded by the context or wait plus Reboottimeout.
+func VerifyContext(ctx context.Context, addrStr string, bcastStr string, mac string, exp Expect, wait time.Duration, debug string) (res Verifyresult, er error) {
+	log.Printf("            Verify: (%s) %s -> %s\n", mac, addrStr, bcastStr)
+	start := time.Now()
+	res.Macaddress = mac
+	res.Expect = exp
+
+	ctx, cancel := withdefault(ctx, wait+Reboottimeout)
+	defer cancel()
+
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+		res.Message = "Canceled before the board rebooted"
+		return res, ctx.Err()
+	}
+
+	for {
+		dctx, dcancel := context.WithTimeout(ctx, Discoverwindow)
+		strs, err := DiscoverContext(dctx, addrStr, bcastStr, debug)
+		dcancel()
+		if err != nil && !errors.Is(err, ErrTimeout) {
+			res.Message = err.Error()
+			return res, err
+		}
+		if str, err := Findboard(strs, mac); err == nil {
+			res.Found = true
+			res.Board = str
+			break
+		}
+		if ctx.Err() != nil {
+			res.Seconds = time.Since(start).Seconds()
+			res.Message = "Board did not come back after programming"
+			return res, &BoardError{Macaddress: mac, Addr: bcastStr}
+		}
+	}
+
+	res.Passed = true
+	res.check("Board", exp.Board, res.Board.Board)
+	res.check("Firmware", exp.Firmware, res.Board.Firmware)
+	res.check("Protocol", exp.Protocol, res.Board.Protocol)
+	res.Seconds = time.Since(start).Seconds()
+
+	if !res.Passed {
+		res.Message = "Board answered with unexpected values"
+		return res, fmt.Errorf("%w: board (%s)", ErrVerifyFailed, mac)
+	}
+	res.Message = "Board answered with the expected values"
+	return res, nil
+}
+
+func (res *Verifyresult) check(field string, want string, got string) {
+	if want == "" {
+		return
+	}
+	c := Verifycheck{Field: field, Want: want, Got: got, Passed: strings.EqualFold(want, got)}
+	if !c.Passed {
+		res.Passed = false
+	}
+	res.Checks = append(res.Checks, c)
+}
@@ -1,1936 +1,496 @@
-
-
-
-
-
-
-<!DOCTYPE html>
-<html lang="en">
-  <head>
-    <meta charset="utf-8">
-
-
-
-  <link crossorigin="anonymous" href="https://assets-cdn.github.com/assets/frameworks-81a59bf26d881d29286674f6deefe779c444382fff322085b50ba455460ccae5.css" integrity="sha256-gaWb8m2IHSkoZnT23u/necREOC//MiCFtQukVUYMyuU=" media="all" rel="stylesheet" />
-  <link crossorigin="anonymous" href="https://assets-cdn.github.com/assets/github-4bfc441c8c45176d1c43ba2ec0cddd3c6c199d73eefee890f7502bd87d5882c3.css" integrity="sha256-S/xEHIxFF20cQ7ouwM3dPGwZnXPu/uiQ91Ar2H1YgsM=" media="all" rel="stylesheet" />
-  
-  
-  <link crossorigin="anonymous" href="https://assets-cdn.github.com/assets/site-7d9c6bd23286465361abfa183deb8a05eabb77438ab42033e8230c0c0768d539.css" integrity="sha256-fZxr0jKGRlNhq/oYPeuKBeq7d0OKtCAz6CMMDAdo1Tk=" media="all" rel="stylesheet" />
-  
-
-  <meta name="viewport" content="width=device-width">
-  
-  <title>OpenHPSDR-Protocol2-Programmers/HPSDRProgrammer_cmd.go at master · TAPR/OpenHPSDR-Protocol2-Programmers · GitHub</title>
-  <link rel="search" type="application/opensearchdescription+xml" href="/opensearch.xml" title="GitHub">
-  <link rel="fluid-icon" href="https://github.com/fluidicon.png" title="GitHub">
-  <meta property="fb:app_id" content="1401488693436528">
-
-    
-    <meta content="https://avatars3.githubusercontent.com/u/14286648?v=3&amp;s=400" property="og:image" /><meta content="GitHub" property="og:site_name" /><meta content="object" property="og:type" /><meta content="TAPR/OpenHPSDR-Protocol2-Programmers" property="og:title" /><meta content="https://github.com/TAPR/OpenHPSDR-Protocol2-Programmers" property="og:url" /><meta content="OpenHPSDR-Protocol2-Programmers - OpenHPSDR firmware programmers for the new protocol (protocol2)" property="og:description" />
-
-  <link rel="assets" href="https://assets-cdn.github.com/">
-  
-  <meta name="pjax-timeout" content="1000">
-  
-  <meta name="request-id" content="E638:21B3A:547FB67:7E9FAA2:59110B4D" data-pjax-transient>
-  
-
-  <meta name="selected-link" value="repo_source" data-pjax-transient>
-
-  <meta name="google-site-verification" content="KT5gs8h0wvaagLKAVWq8bbeNwnZZK1r1XQysX3xurLU">
-<meta name="google-site-verification" content="ZzhVyEFwb7w3e0-uOTltm8Jsck2F5StVihD0exw2fsA">
-    <meta name="google-analytics" content="UA-3769691-2">
-
-<meta content="collector.githubapp.com" name="octolytics-host" /><meta content="github" name="octolytics-app-id" /><meta content="https://collector.githubapp.com/github-external/browser_event" name="octolytics-event-url" /><meta content="E638:21B3A:547FB67:7E9FAA2:59110B4D" name="octolytics-dimension-request_id" />
-<meta content="/&lt;user-name&gt;/&lt;repo-name&gt;/blob/show" data-pjax-transient="true" name="analytics-location" />
-
-
-
-
-  <meta class="js-ga-set" name="dimension1" content="Logged Out">
-
-
-  
-
-      <meta name="hostname" content="github.com">
-  <meta name="user-login" content="">
-
-      <meta name="expected-hostname" content="github.com">
-    <meta name="js-proxy-site-detection-payload" content="ZmU5YjQyYzYxMDY2MGU0NTA4NzQyMTFhMmM3MmYxNmE1NDE5Nzg5YTZmNTM4OTZmMjlkYzZkNjJjNDJjOWMyOHx7InJlbW90ZV9hZGRyZXNzIjoiMTczLjI3LjkxLjE1MSIsInJlcXVlc3RfaWQiOiJFNjM4OjIxQjNBOjU0N0ZCNjc6N0U5RkFBMjo1OTExMEI0RCIsInRpbWVzdGFtcCI6MTQ5NDI4OTIzMCwiaG9zdCI6ImdpdGh1Yi5jb20ifQ==">
-
-
-  <meta name="html-safe-nonce" content="ceac3bb5fe757ea06e788e62200bb6f76f409ed1">
-
-  <meta http-equiv="x-pjax-version" content="c0153700f1f96b8b64e61be1d40e43e4">
-  
-
-    
-  <meta name="description" content="OpenHPSDR-Protocol2-Programmers - OpenHPSDR firmware programmers for the new protocol (protocol2)">
-  <meta name="go-import" content="github.com/TAPR/OpenHPSDR-Protocol2-Programmers git https://github.com/TAPR/OpenHPSDR-Protocol2-Programmers.git">
-
-  <meta content="14286648" name="octolytics-dimension-user_id" /><meta content="TAPR" name="octolytics-dimension-user_login" /><meta content="83981317" name="octolytics-dimension-repository_id" /><meta content="TAPR/OpenHPSDR-Protocol2-Programmers" name="octolytics-dimension-repository_nwo" /><meta content="true" name="octolytics-dimension-repository_public" /><meta content="false" name="octolytics-dimension-repository_is_fork" /><meta content="83981317" name="octolytics-dimension-repository_network_root_id" /><meta content="TAPR/OpenHPSDR-Protocol2-Programmers" name="octolytics-dimension-repository_network_root_nwo" />
-  <link href="https://github.com/TAPR/OpenHPSDR-Protocol2-Programmers/commits/master.atom" rel="alternate" title="Recent Commits to OpenHPSDR-Protocol2-Programmers:master" type="application/atom+xml">
-
-
-    <link rel="canonical" href="https://github.com/TAPR/OpenHPSDR-Protocol2-Programmers/blob/master/newradio/HPSDRProgrammer_cmd.go" data-pjax-transient>
-
-
-  <meta name="browser-stats-url" content="https://api.github.com/_private/browser/stats">
-
-  <meta name="browser-errors-url" content="https://api.github.com/_private/browser/errors">
-
-  <link rel="mask-icon" href="https://assets-cdn.github.com/pinned-octocat.svg" color="#000000">
-  <link rel="icon" type="image/x-icon" href="https://assets-cdn.github.com/favicon.ico">
-
-<meta name="theme-color" content="#1e2327">
-
-
-
-  </head>
-
-  <body class="logged-out env-production page-blob">
-    
-
-
-  <div class="position-relative js-header-wrapper ">
-    <a href="#start-of-content" tabindex="1" class="accessibility-aid js-skip-to-content">Skip to content</a>
-    <div id="js-pjax-loader-bar" class="pjax-loader-bar"><div class="progress"></div></div>
-
-    
-    
-    
-
-
-
-          <header class="site-header js-details-container Details" role="banner">
-  <div class="container-responsive">
-    <a class="header-logo-invertocat" href="https://github.com/" aria-label="Homepage" data-ga-click="(Logged out) Header, go to homepage, icon:logo-wordmark">
-      <svg aria-hidden="true" class="octicon octicon-mark-github" height="32" version="1.1" viewBox="0 0 16 16" width="32"><path fill-rule="evenodd" d="M8 0C3.58 0 0 3.58 0 8c0 3.54 2.29 6.53 5.47 7.59.4.07.55-.17.55-.38 0-.19-.01-.82-.01-1.49-2.01.37-2.53-.49-2.69-.94-.09-.23-.48-.94-.82-1.13-.28-.15-.68-.52-.01-.53.63-.01 1.08.58 1.23.82.72 1.21 1.87.87 2.33.66.07-.52.28-.87.51-1.07-1.78-.2-3.64-.89-3.64-3.95 0-.87.31-1.59.82-2.15-.08-.2-.36-1.02.08-2.12 0 0 .67-.21 2.2.82.64-.18 1.32-.27 2-.27.68 0 1.36.09 2 .27 1.53-1.04 2.2-.82 2.2-.82.44 1.1.16 1.92.08 2.12.51.56.82 1.27.82 2.15 0 3.07-1.87 3.75-3.65 3.95.29.25.54.73.54 1.48 0 1.07-.01 1.93-.01 2.2 0 .21.15.46.55.38A8.013 8.013 0 0 0 16 8c0-4.42-3.58-8-8-8z"/></svg>
-    </a>
-
-    <button class="btn-link float-right site-header-toggle js-details-target" type="button" aria-label="Toggle navigation">
-      <svg aria-hidden="true" class="octicon octicon-three-bars" height="24" version="1.1" viewBox="0 0 12 16" width="18"><path fill-rule="evenodd" d="M11.41 9H.59C0 9 0 8.59 0 8c0-.59 0-1 .59-1H11.4c.59 0 .59.41.59 1 0 .59 0 1-.59 1h.01zm0-4H.59C0 5 0 4.59 0 4c0-.59 0-1 .59-1H11.4c.59 0 .59.41.59 1 0 .59 0 1-.59 1h.01zM.59 11H11.4c.59 0 .59.41.59 1 0 .59 0 1-.59 1H.59C0 13 0 12.59 0 12c0-.59 0-1 .59-1z"/></svg>
-    </button>
-
-    <div class="site-header-menu">
-      <nav class="site-header-nav">
-        <a href="/features" class="js-selected-navigation-item nav-item" data-ga-click="Header, click, Nav menu - item:features" data-selected-links="/features /features">
-          Features
-</a>        <a href="/business" class="js-selected-navigation-item nav-item" data-ga-click="Header, click, Nav menu - item:business" data-selected-links="/business /business/security /business/customers /business">
-          Business
-</a>        <a href="/explore" class="js-selected-navigation-item nav-item" data-ga-click="Header, click, Nav menu - item:explore" data-selected-links="/explore /trending /trending/developers /integrations /integrations/feature/code /integrations/feature/collaborate /integrations/feature/ship /showcases /explore">
-          Explore
-</a>        <a href="/pricing" class="js-selected-navigation-item nav-item" data-ga-click="Header, click, Nav menu - item:pricing" data-selected-links="/pricing /pricing/developer /pricing/team /pricing/business-hosted /pricing/business-enterprise /pricing">
-          Pricing
-</a>      </nav>
-
-      <div class="site-header-actions">
-          <div class="header-search scoped-search site-scoped-search js-site-search" role="search">
-  <!-- '"` --><!-- </textarea></xmp> --></option></form><form accept-charset="UTF-8" action="/TAPR/OpenHPSDR-Protocol2-Programmers/search" class="js-site-search-form" data-scoped-search-url="/TAPR/OpenHPSDR-Protocol2-Programmers/search" data-unscoped-search-url="/search" method="get"><div style="margin:0;padding:0;display:inline"><input name="utf8" type="hidden" value="&#x2713;" /></div>
-    <label class="form-control header-search-wrapper js-chromeless-input-container">
-        <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/blob/master/newradio/HPSDRProgrammer_cmd.go" class="header-search-scope no-underline">This repository</a>
-      <input type="text"
-        class="form-control header-search-input js-site-search-focus js-site-search-field is-clearable"
-        data-hotkey="s"
-        name="q"
-        value=""
-        placeholder="Search"
-        aria-label="Search this repository"
-        data-unscoped-placeholder="Search GitHub"
-        data-scoped-placeholder="Search"
-        autocapitalize="off">
-        <input type="hidden" class="js-site-search-type-field" name="type" >
-    </label>
-</form></div>
-
-
-          <a class="text-bold site-header-link" href="/login?return_to=%2FTAPR%2FOpenHPSDR-Protocol2-Programmers%2Fblob%2Fmaster%2Fnewradio%2FHPSDRProgrammer_cmd.go" data-ga-click="(Logged out) Header, clicked Sign in, text:sign-in">Sign in</a>
-            <span class="text-gray">or</span>
-            <a class="text-bold site-header-link" href="/join?source=header-repo" data-ga-click="(Logged out) Header, clicked Sign up, text:sign-up">Sign up</a>
-      </div>
-    </div>
-  </div>
-</header>
-
-
-  </div>
-
-  <div id="start-of-content" class="accessibility-aid"></div>
-
-    <div id="js-flash-container">
-</div>
-
-
-
-  <div role="main">
-        <div itemscope itemtype="http://schema.org/SoftwareSourceCode">
-    <div id="js-repo-pjax-container" data-pjax-container>
-        
-
-
-
-
-    <div class="pagehead repohead instapaper_ignore readability-menu experiment-repo-nav">
-      <div class="container repohead-details-container">
-
-        <ul class="pagehead-actions">
-  <li>
-      <a href="/login?return_to=%2FTAPR%2FOpenHPSDR-Protocol2-Programmers"
-    class="btn btn-sm btn-with-count tooltipped tooltipped-n"
-    aria-label="You must be signed in to watch a repository" rel="nofollow">
-    <svg aria-hidden="true" class="octicon octicon-eye" height="16" version="1.1" viewBox="0 0 16 16" width="16"><path fill-rule="evenodd" d="M8.06 2C3 2 0 8 0 8s3 6 8.06 6C13 14 16 8 16 8s-3-6-7.94-6zM8 12c-2.2 0-4-1.78-4-4 0-2.2 1.8-4 4-4 2.22 0 4 1.8 4 4 0 2.22-1.78 4-4 4zm2-4c0 1.11-.89 2-2 2-1.11 0-2-.89-2-2 0-1.11.89-2 2-2 1.11 0 2 .89 2 2z"/></svg>
-    Watch
-  </a>
-  <a class="social-count" href="/TAPR/OpenHPSDR-Protocol2-Programmers/watchers"
-     aria-label="5 users are watching this repository">
-    5
-  </a>
-
-  </li>
-
-  <li>
-      <a href="/login?return_to=%2FTAPR%2FOpenHPSDR-Protocol2-Programmers"
-    class="btn btn-sm btn-with-count tooltipped tooltipped-n"
-    aria-label="You must be signed in to star a repository" rel="nofollow">
-    <svg aria-hidden="true" class="octicon octicon-star" height="16" version="1.1" viewBox="0 0 14 16" width="14"><path fill-rule="evenodd" d="M14 6l-4.9-.64L7 1 4.9 5.36 0 6l3.6 3.26L2.67 14 7 11.67 11.33 14l-.93-4.74z"/></svg>
-    Star
-  </a>
-
-    <a class="social-count js-social-count" href="/TAPR/OpenHPSDR-Protocol2-Programmers/stargazers"
-      aria-label="0 users starred this repository">
-      0
-    </a>
-
-  </li>
-
-  <li>
-      <a href="/login?return_to=%2FTAPR%2FOpenHPSDR-Protocol2-Programmers"
-        class="btn btn-sm btn-with-count tooltipped tooltipped-n"
-        aria-label="You must be signed in to fork a repository" rel="nofollow">
-        <svg aria-hidden="true" class="octicon octicon-repo-forked" height="16" version="1.1" viewBox="0 0 10 16" width="10"><path fill-rule="evenodd" d="M8 1a1.993 1.993 0 0 0-1 3.72V6L5 8 3 6V4.72A1.993 1.993 0 0 0 2 1a1.993 1.993 0 0 0-1 3.72V6.5l3 3v1.78A1.993 1.993 0 0 0 5 15a1.993 1.993 0 0 0 1-3.72V9.5l3-3V4.72A1.993 1.993 0 0 0 8 1zM2 4.2C1.34 4.2.8 3.65.8 3c0-.65.55-1.2 1.2-1.2.65 0 1.2.55 1.2 1.2 0 .65-.55 1.2-1.2 1.2zm3 10c-.66 0-1.2-.55-1.2-1.2 0-.65.55-1.2 1.2-1.2.65 0 1.2.55 1.2 1.2 0 .65-.55 1.2-1.2 1.2zm3-10c-.66 0-1.2-.55-1.2-1.2 0-.65.55-1.2 1.2-1.2.65 0 1.2.55 1.2 1.2 0 .65-.55 1.2-1.2 1.2z"/></svg>
-        Fork
-      </a>
-
-    <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/network" class="social-count"
-       aria-label="0 users forked this repository">
-      0
-    </a>
-  </li>
-</ul>
-
-        <h1 class="public ">
-  <svg aria-hidden="true" class="octicon octicon-repo" height="16" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M4 9H3V8h1v1zm0-3H3v1h1V6zm0-2H3v1h1V4zm0-2H3v1h1V2zm8-1v12c0 .55-.45 1-1 1H6v2l-1.5-1.5L3 16v-2H1c-.55 0-1-.45-1-1V1c0-.55.45-1 1-1h10c.55 0 1 .45 1 1zm-1 10H1v2h2v-1h3v1h5v-2zm0-10H2v9h9V1z"/></svg>
-  <span class="author" itemprop="author"><a href="/TAPR" class="url fn" rel="author">TAPR</a></span><!--
---><span class="path-divider">/</span><!--
---><strong itemprop="name"><a href="/TAPR/OpenHPSDR-Protocol2-Programmers" data-pjax="#js-repo-pjax-container">OpenHPSDR-Protocol2-Programmers</a></strong>
-
-</h1>
-
-      </div>
-      <div class="container">
-        
-<nav class="reponav js-repo-nav js-sidenav-container-pjax"
-     itemscope
-     itemtype="http://schema.org/BreadcrumbList"
-     role="navigation"
-     data-pjax="#js-repo-pjax-container">
-
-  <span itemscope itemtype="http://schema.org/ListItem" itemprop="itemListElement">
-    <a href="/TAPR/OpenHPSDR-Protocol2-Programmers" class="js-selected-navigation-item selected reponav-item" data-hotkey="g c" data-selected-links="repo_source repo_downloads repo_commits repo_releases repo_tags repo_branches /TAPR/OpenHPSDR-Protocol2-Programmers" itemprop="url">
-      <svg aria-hidden="true" class="octicon octicon-code" height="16" version="1.1" viewBox="0 0 14 16" width="14"><path fill-rule="evenodd" d="M9.5 3L8 4.5 11.5 8 8 11.5 9.5 13 14 8 9.5 3zm-5 0L0 8l4.5 5L6 11.5 2.5 8 6 4.5 4.5 3z"/></svg>
-      <span itemprop="name">Code</span>
-      <meta itemprop="position" content="1">
-</a>  </span>
-
-    <span itemscope itemtype="http://schema.org/ListItem" itemprop="itemListElement">
-      <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/issues" class="js-selected-navigation-item reponav-item" data-hotkey="g i" data-selected-links="repo_issues repo_labels repo_milestones /TAPR/OpenHPSDR-Protocol2-Programmers/issues" itemprop="url">
-        <svg aria-hidden="true" class="octicon octicon-issue-opened" height="16" version="1.1" viewBox="0 0 14 16" width="14"><path fill-rule="evenodd" d="M7 2.3c3.14 0 5.7 2.56 5.7 5.7s-2.56 5.7-5.7 5.7A5.71 5.71 0 0 1 1.3 8c0-3.14 2.56-5.7 5.7-5.7zM7 1C3.14 1 0 4.14 0 8s3.14 7 7 7 7-3.14 7-7-3.14-7-7-7zm1 3H6v5h2V4zm0 6H6v2h2v-2z"/></svg>
-        <span itemprop="name">Issues</span>
-        <span class="Counter">0</span>
-        <meta itemprop="position" content="2">
-</a>    </span>
-
-  <span itemscope itemtype="http://schema.org/ListItem" itemprop="itemListElement">
-    <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/pulls" class="js-selected-navigation-item reponav-item" data-hotkey="g p" data-selected-links="repo_pulls /TAPR/OpenHPSDR-Protocol2-Programmers/pulls" itemprop="url">
-      <svg aria-hidden="true" class="octicon octicon-git-pull-request" height="16" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M11 11.28V5c-.03-.78-.34-1.47-.94-2.06C9.46 2.35 8.78 2.03 8 2H7V0L4 3l3 3V4h1c.27.02.48.11.69.31.21.2.3.42.31.69v6.28A1.993 1.993 0 0 0 10 15a1.993 1.993 0 0 0 1-3.72zm-1 2.92c-.66 0-1.2-.55-1.2-1.2 0-.65.55-1.2 1.2-1.2.65 0 1.2.55 1.2 1.2 0 .65-.55 1.2-1.2 1.2zM4 3c0-1.11-.89-2-2-2a1.993 1.993 0 0 0-1 3.72v6.56A1.993 1.993 0 0 0 2 15a1.993 1.993 0 0 0 1-3.72V4.72c.59-.34 1-.98 1-1.72zm-.8 10c0 .66-.55 1.2-1.2 1.2-.65 0-1.2-.55-1.2-1.2 0-.65.55-1.2 1.2-1.2.65 0 1.2.55 1.2 1.2zM2 4.2C1.34 4.2.8 3.65.8 3c0-.65.55-1.2 1.2-1.2.65 0 1.2.55 1.2 1.2 0 .65-.55 1.2-1.2 1.2z"/></svg>
-      <span itemprop="name">Pull requests</span>
-      <span class="Counter">0</span>
-      <meta itemprop="position" content="3">
-</a>  </span>
-
-    <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/projects" class="js-selected-navigation-item reponav-item" data-selected-links="repo_projects new_repo_project repo_project /TAPR/OpenHPSDR-Protocol2-Programmers/projects">
-      <svg aria-hidden="true" class="octicon octicon-project" height="16" version="1.1" viewBox="0 0 15 16" width="15"><path fill-rule="evenodd" d="M10 12h3V2h-3v10zm-4-2h3V2H6v8zm-4 4h3V2H2v12zm-1 1h13V1H1v14zM14 0H1a1 1 0 0 0-1 1v14a1 1 0 0 0 1 1h13a1 1 0 0 0 1-1V1a1 1 0 0 0-1-1z"/></svg>
-      Projects
-      <span class="Counter" >0</span>
-</a>
-
-
-
-  <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/pulse" class="js-selected-navigation-item reponav-item" data-selected-links="pulse /TAPR/OpenHPSDR-Protocol2-Programmers/pulse">
-    <svg aria-hidden="true" class="octicon octicon-pulse" height="16" version="1.1" viewBox="0 0 14 16" width="14"><path fill-rule="evenodd" d="M11.5 8L8.8 5.4 6.6 8.5 5.5 1.6 2.38 8H0v2h3.6l.9-1.8.9 5.4L9 8.5l1.6 1.5H14V8z"/></svg>
-    Pulse
-</a>
-  <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/graphs" class="js-selected-navigation-item reponav-item" data-selected-links="repo_graphs repo_contributors /TAPR/OpenHPSDR-Protocol2-Programmers/graphs">
-    <svg aria-hidden="true" class="octicon octicon-graph" height="16" version="1.1" viewBox="0 0 16 16" width="16"><path fill-rule="evenodd" d="M16 14v1H0V0h1v14h15zM5 13H3V8h2v5zm4 0H7V3h2v10zm4 0h-2V6h2v7z"/></svg>
-    Graphs
-</a>
-
-</nav>
-
-      </div>
-    </div>
-
-<div class="container new-discussion-timeline experiment-repo-nav">
-  <div class="repository-content">
-
-    
-          
-
-<a href="/TAPR/OpenHPSDR-Protocol2-Programmers/blob/2aad9a20a07f2e60b07f01ea2665ae08a198be1c/newradio/HPSDRProgrammer_cmd.go" class="d-none js-permalink-shortcut" data-hotkey="y">Permalink</a>
-
-<!-- blob contrib key: blob_contributors:v21:eb275b53b75197534f3fb7ff1f79f531 -->
-
-<div class="file-navigation js-zeroclipboard-container">
-  
-<div class="select-menu branch-select-menu js-menu-container js-select-menu float-left">
-  <button class=" btn btn-sm select-menu-button js-menu-target css-truncate" data-hotkey="w"
-    
-    type="button" aria-label="Switch branches or tags" tabindex="0" aria-haspopup="true">
-      <i>Branch:</i>
-      <span class="js-select-button css-truncate-target">master</span>
-  </button>
-
-  <div class="select-menu-modal-holder js-menu-content js-navigation-container" data-pjax>
-
-    <div class="select-menu-modal">
-      <div class="select-menu-header">
-        <svg aria-label="Close" class="octicon octicon-x js-menu-close" height="16" role="img" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M7.48 8l3.75 3.75-1.48 1.48L6 9.48l-3.75 3.75-1.48-1.48L4.52 8 .77 4.25l1.48-1.48L6 6.52l3.75-3.75 1.48 1.48z"/></svg>
-        <span class="select-menu-title">Switch branches/tags</span>
-      </div>
-
-      <div class="select-menu-filters">
-        <div class="select-menu-text-filter">
-          <input type="text" aria-label="Filter branches/tags" id="context-commitish-filter-field" class="form-control js-filterable-field js-navigation-enable" placeholder="Filter branches/tags">
-        </div>
-        <div class="select-menu-tabs">
-          <ul>
-            <li class="select-menu-tab">
-              <a href="#" data-tab-filter="branches" data-filter-placeholder="Filter branches/tags" class="js-select-menu-tab" role="tab">Branches</a>
-            </li>
-            <li class="select-menu-tab">
-              <a href="#" data-tab-filter="tags" data-filter-placeholder="Find a tag…" class="js-select-menu-tab" role="tab">Tags</a>
-            </li>
-          </ul>
-        </div>
-      </div>
-
-      <div class="select-menu-list select-menu-tab-bucket js-select-menu-tab-bucket" data-tab-filter="branches" role="menu">
-
-        <div data-filterable-for="context-commitish-filter-field" data-filterable-type="substring">
-
-
-            <a class="select-menu-item js-navigation-item js-navigation-open selected"
-               href="/TAPR/OpenHPSDR-Protocol2-Programmers/blob/master/newradio/HPSDRProgrammer_cmd.go"
-               data-name="master"
-               data-skip-pjax="true"
-               rel="nofollow">
-              <svg aria-hidden="true" class="octicon octicon-check select-menu-item-icon" height="16" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M12 5l-8 8-4-4 1.5-1.5L4 10l6.5-6.5z"/></svg>
-              <span class="select-menu-item-text css-truncate-target js-select-menu-filter-text">
-                master
-              </span>
-            </a>
-        </div>
-
-          <div class="select-menu-no-results">Nothing to show</div>
-      </div>
-
-      <div class="select-menu-list select-menu-tab-bucket js-select-menu-tab-bucket" data-tab-filter="tags">
-        <div data-filterable-for="context-commitish-filter-field" data-filterable-type="substring">
-
-
-            <a class="select-menu-item js-navigation-item js-navigation-open "
-              href="/TAPR/OpenHPSDR-Protocol2-Programmers/tree/v0.2.7/newradio/HPSDRProgrammer_cmd.go"
-              data-name="v0.2.7"
-              data-skip-pjax="true"
-              rel="nofollow">
-              <svg aria-hidden="true" class="octicon octicon-check select-menu-item-icon" height="16" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M12 5l-8 8-4-4 1.5-1.5L4 10l6.5-6.5z"/></svg>
-              <span class="select-menu-item-text css-truncate-target" title="v0.2.7">
-                v0.2.7
-              </span>
-            </a>
-        </div>
-
-        <div class="select-menu-no-results">Nothing to show</div>
-      </div>
-
-    </div>
-  </div>
-</div>
-
-  <div class="BtnGroup float-right">
-    <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/find/master"
-          class="js-pjax-capture-input btn btn-sm BtnGroup-item"
-          data-pjax
-          data-hotkey="t">
-      Find file
-    </a>
-    <button aria-label="Copy file path to clipboard" class="js-zeroclipboard btn btn-sm BtnGroup-item tooltipped tooltipped-s" data-copied-hint="Copied!" type="button">Copy path</button>
-  </div>
-  <div class="breadcrumb js-zeroclipboard-target">
-    <span class="repo-root js-repo-root"><span class="js-path-segment"><a href="/TAPR/OpenHPSDR-Protocol2-Programmers"><span>OpenHPSDR-Protocol2-Programmers</span></a></span></span><span class="separator">/</span><span class="js-path-segment"><a href="/TAPR/OpenHPSDR-Protocol2-Programmers/tree/master/newradio"><span>newradio</span></a></span><span class="separator">/</span><strong class="final-path">HPSDRProgrammer_cmd.go</strong>
-  </div>
-</div>
-
-
-<include-fragment class="commit-tease" src="/TAPR/OpenHPSDR-Protocol2-Programmers/contributors/master/newradio/HPSDRProgrammer_cmd.go">
-  <div>
-    Fetching contributors&hellip;
-  </div>
-
-  <div class="commit-tease-contributors">
-    <img alt="" class="loader-loading float-left" height="16" src="https://assets-cdn.github.com/images/spinners/octocat-spinner-32-EAF2F5.gif" width="16" />
-    <span class="loader-error">Cannot retrieve contributors at this time</span>
-  </div>
-</include-fragment>
-<div class="file">
-  <div class="file-header">
-  <div class="file-actions">
-
-    <div class="BtnGroup">
-      <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/raw/master/newradio/HPSDRProgrammer_cmd.go" class="btn btn-sm BtnGroup-item" id="raw-url">Raw</a>
-        <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/blame/master/newradio/HPSDRProgrammer_cmd.go" class="btn btn-sm js-update-url-with-hash BtnGroup-item" data-hotkey="b">Blame</a>
-      <a href="/TAPR/OpenHPSDR-Protocol2-Programmers/commits/master/newradio/HPSDRProgrammer_cmd.go" class="btn btn-sm BtnGroup-item" rel="nofollow">History</a>
-    </div>
-
-
-        <button type="button" class="btn-octicon disabled tooltipped tooltipped-nw"
-          aria-label="You must be signed in to make or propose changes">
-          <svg aria-hidden="true" class="octicon octicon-pencil" height="16" version="1.1" viewBox="0 0 14 16" width="14"><path fill-rule="evenodd" d="M0 12v3h3l8-8-3-3-8 8zm3 2H1v-2h1v1h1v1zm10.3-9.3L12 6 9 3l1.3-1.3a.996.996 0 0 1 1.41 0l1.59 1.59c.39.39.39 1.02 0 1.41z"/></svg>
-        </button>
-        <button type="button" class="btn-octicon btn-octicon-danger disabled tooltipped tooltipped-nw"
-          aria-label="You must be signed in to make or propose changes">
-          <svg aria-hidden="true" class="octicon octicon-trashcan" height="16" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M11 2H9c0-.55-.45-1-1-1H5c-.55 0-1 .45-1 1H2c-.55 0-1 .45-1 1v1c0 .55.45 1 1 1v9c0 .55.45 1 1 1h7c.55 0 1-.45 1-1V5c.55 0 1-.45 1-1V3c0-.55-.45-1-1-1zm-1 12H3V5h1v8h1V5h1v8h1V5h1v8h1V5h1v9zm1-10H2V3h9v1z"/></svg>
-        </button>
-  </div>
-
-  <div class="file-info">
-      346 lines (303 sloc)
-      <span class="file-info-divider"></span>
-    9.51 KB
-  </div>
-</div>
-
-  
-
-  <div itemprop="text" class="blob-wrapper data type-go">
-      <table class="highlight tab-size js-file-line-container" data-tab-size="8">
-      <tr>
-        <td id="L1" class="blob-num js-line-number" data-line-number="1"></td>
-        <td id="LC1" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span> Program to program HPSDR boards from the command line</span></td>
-      </tr>
-      <tr>
-        <td id="L2" class="blob-num js-line-number" data-line-number="2"></td>
-        <td id="LC2" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span> new protocol version</span></td>
-      </tr>
-      <tr>
-        <td id="L3" class="blob-num js-line-number" data-line-number="3"></td>
-        <td id="LC3" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L4" class="blob-num js-line-number" data-line-number="4"></td>
-        <td id="LC4" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span> by David R. Larsen KV0S, Copyright 2014-11-24</span></td>
-      </tr>
-      <tr>
-        <td id="L5" class="blob-num js-line-number" data-line-number="5"></td>
-        <td id="LC5" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span></span></td>
-      </tr>
-      <tr>
-        <td id="L6" class="blob-num js-line-number" data-line-number="6"></td>
-        <td id="LC6" class="blob-code blob-code-inner js-file-line"><span class="pl-k">package</span> main</td>
-      </tr>
-      <tr>
-        <td id="L7" class="blob-num js-line-number" data-line-number="7"></td>
-        <td id="LC7" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L8" class="blob-num js-line-number" data-line-number="8"></td>
-        <td id="LC8" class="blob-code blob-code-inner js-file-line"><span class="pl-k">import</span> (</td>
-      </tr>
-      <tr>
-        <td id="L9" class="blob-num js-line-number" data-line-number="9"></td>
-        <td id="LC9" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>encoding/json<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L10" class="blob-num js-line-number" data-line-number="10"></td>
-        <td id="LC10" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>flag<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L11" class="blob-num js-line-number" data-line-number="11"></td>
-        <td id="LC11" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>fmt<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L12" class="blob-num js-line-number" data-line-number="12"></td>
-        <td id="LC12" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>io/ioutil<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L13" class="blob-num js-line-number" data-line-number="13"></td>
-        <td id="LC13" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>log<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L14" class="blob-num js-line-number" data-line-number="14"></td>
-        <td id="LC14" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>os<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L15" class="blob-num js-line-number" data-line-number="15"></td>
-        <td id="LC15" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>os/user<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L16" class="blob-num js-line-number" data-line-number="16"></td>
-        <td id="LC16" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>runtime<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L17" class="blob-num js-line-number" data-line-number="17"></td>
-        <td id="LC17" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>strings<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L18" class="blob-num js-line-number" data-line-number="18"></td>
-        <td id="LC18" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>time<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L19" class="blob-num js-line-number" data-line-number="19"></td>
-        <td id="LC19" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L20" class="blob-num js-line-number" data-line-number="20"></td>
-        <td id="LC20" class="blob-code blob-code-inner js-file-line">	<span class="pl-s"><span class="pl-pds">&quot;</span>oak.snr.missouri.edu/daveradio/newopenhpsdr<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L21" class="blob-num js-line-number" data-line-number="21"></td>
-        <td id="LC21" class="blob-code blob-code-inner js-file-line">)</td>
-      </tr>
-      <tr>
-        <td id="L22" class="blob-num js-line-number" data-line-number="22"></td>
-        <td id="LC22" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L23" class="blob-num js-line-number" data-line-number="23"></td>
-        <td id="LC23" class="blob-code blob-code-inner js-file-line"><span class="pl-k">const</span> version <span class="pl-k">string</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>0.2.8<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L24" class="blob-num js-line-number" data-line-number="24"></td>
-        <td id="LC24" class="blob-code blob-code-inner js-file-line"><span class="pl-k">const</span> protocol <span class="pl-k">string</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>&gt;1.7<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L25" class="blob-num js-line-number" data-line-number="25"></td>
-        <td id="LC25" class="blob-code blob-code-inner js-file-line"><span class="pl-k">const</span> update <span class="pl-k">string</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>2016-9-17<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L26" class="blob-num js-line-number" data-line-number="26"></td>
-        <td id="LC26" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L27" class="blob-num js-line-number" data-line-number="27"></td>
-        <td id="LC27" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span>  global current board</span></td>
-      </tr>
-      <tr>
-        <td id="L28" class="blob-num js-line-number" data-line-number="28"></td>
-        <td id="LC28" class="blob-code blob-code-inner js-file-line"><span class="pl-k">var</span> <span class="pl-smi">crtbd</span> newopenhpsdr.<span class="pl-smi">Hpsdrboard</span></td>
-      </tr>
-      <tr>
-        <td id="L29" class="blob-num js-line-number" data-line-number="29"></td>
-        <td id="LC29" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L30" class="blob-num js-line-number" data-line-number="30"></td>
-        <td id="LC30" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span> function to point users to the command list</span></td>
-      </tr>
-      <tr>
-        <td id="L31" class="blob-num js-line-number" data-line-number="31"></td>
-        <td id="LC31" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">usage</span>() {</td>
-      </tr>
-      <tr>
-        <td id="L32" class="blob-num js-line-number" data-line-number="32"></td>
-        <td id="LC32" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    For a list of commands use -help <span class="pl-cce">\n\n</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L33" class="blob-num js-line-number" data-line-number="33"></td>
-        <td id="LC33" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L34" class="blob-num js-line-number" data-line-number="34"></td>
-        <td id="LC34" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L35" class="blob-num js-line-number" data-line-number="35"></td>
-        <td id="LC35" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span> Function to print the program name info</span></td>
-      </tr>
-      <tr>
-        <td id="L36" class="blob-num js-line-number" data-line-number="36"></td>
-        <td id="LC36" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">program</span>() {</td>
-      </tr>
-      <tr>
-        <td id="L37" class="blob-num js-line-number" data-line-number="37"></td>
-        <td id="LC37" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>HPSDRProgrammer_cmd  version:(<span class="pl-c1">%s</span>)<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, version)</td>
-      </tr>
-      <tr>
-        <td id="L38" class="blob-num js-line-number" data-line-number="38"></td>
-        <td id="LC38" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    By Dave KV0S, 2014-11-24, GPL2 <span class="pl-cce">\n\n</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L39" class="blob-num js-line-number" data-line-number="39"></td>
-        <td id="LC39" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>        Protocol: <span class="pl-c1">%s</span> <span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, protocol)</td>
-      </tr>
-      <tr>
-        <td id="L40" class="blob-num js-line-number" data-line-number="40"></td>
-        <td id="LC40" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    Last Updated: <span class="pl-c1">%s</span> <span class="pl-cce">\n\n</span><span class="pl-pds">&quot;</span></span>, update)</td>
-      </tr>
-      <tr>
-        <td id="L41" class="blob-num js-line-number" data-line-number="41"></td>
-        <td id="LC41" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L42" class="blob-num js-line-number" data-line-number="42"></td>
-        <td id="LC42" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L43" class="blob-num js-line-number" data-line-number="43"></td>
-        <td id="LC43" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span> Convenience function to print board data</span></td>
-      </tr>
-      <tr>
-        <td id="L44" class="blob-num js-line-number" data-line-number="44"></td>
-        <td id="LC44" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">Listboard</span>(<span class="pl-v">str</span> <span class="pl-v">newopenhpsdr</span>.<span class="pl-v">Hpsdrboard</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L45" class="blob-num js-line-number" data-line-number="45"></td>
-        <td id="LC45" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> str.<span class="pl-smi">Macaddress</span> != <span class="pl-s"><span class="pl-pds">&quot;</span>0:0:0:0:0:0<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L46" class="blob-num js-line-number" data-line-number="46"></td>
-        <td id="LC46" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L47" class="blob-num js-line-number" data-line-number="47"></td>
-        <td id="LC47" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>        Board Type: <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Board</span>)</td>
-      </tr>
-      <tr>
-        <td id="L48" class="blob-num js-line-number" data-line-number="48"></td>
-        <td id="LC48" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>       HPSDR Board: (<span class="pl-c1">%s</span>)<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Macaddress</span>)</td>
-      </tr>
-      <tr>
-        <td id="L49" class="blob-num js-line-number" data-line-number="49"></td>
-        <td id="LC49" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>     Board Address: <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Baddress</span>)</td>
-      </tr>
-      <tr>
-        <td id="L50" class="blob-num js-line-number" data-line-number="50"></td>
-        <td id="LC50" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>          Protocol: <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Protocol</span>)</td>
-      </tr>
-      <tr>
-        <td id="L51" class="blob-num js-line-number" data-line-number="51"></td>
-        <td id="LC51" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>          Firmware: <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Firmware</span>)</td>
-      </tr>
-      <tr>
-        <td id="L52" class="blob-num js-line-number" data-line-number="52"></td>
-        <td id="LC52" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>         Receivers: <span class="pl-c1">%d</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Receivers</span>)</td>
-      </tr>
-      <tr>
-        <td id="L53" class="blob-num js-line-number" data-line-number="53"></td>
-        <td id="LC53" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>       Freq. Input: <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Freqinput</span>)</td>
-      </tr>
-      <tr>
-        <td id="L54" class="blob-num js-line-number" data-line-number="54"></td>
-        <td id="LC54" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    IQ data format: <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Iqdata</span>)</td>
-      </tr>
-      <tr>
-        <td id="L55" class="blob-num js-line-number" data-line-number="55"></td>
-        <td id="LC55" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>            Status: <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, str.<span class="pl-smi">Status</span>)</td>
-      </tr>
-      <tr>
-        <td id="L56" class="blob-num js-line-number" data-line-number="56"></td>
-        <td id="LC56" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L57" class="blob-num js-line-number" data-line-number="57"></td>
-        <td id="LC57" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L58" class="blob-num js-line-number" data-line-number="58"></td>
-        <td id="LC58" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L59" class="blob-num js-line-number" data-line-number="59"></td>
-        <td id="LC59" class="blob-code blob-code-inner js-file-line"><span class="pl-c"><span class="pl-c">//</span> Convenience function to print interface data</span></td>
-      </tr>
-      <tr>
-        <td id="L60" class="blob-num js-line-number" data-line-number="60"></td>
-        <td id="LC60" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">Listinterface</span>(<span class="pl-v">itr</span> <span class="pl-v">newopenhpsdr</span>.<span class="pl-v">Intface</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L61" class="blob-num js-line-number" data-line-number="61"></td>
-        <td id="LC61" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>          Computer: (<span class="pl-c1">%v</span>)<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, itr.<span class="pl-smi">MAC</span>)</td>
-      </tr>
-      <tr>
-        <td id="L62" class="blob-num js-line-number" data-line-number="62"></td>
-        <td id="LC62" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>                OS: <span class="pl-c1">%s</span> (<span class="pl-c1">%s</span>) <span class="pl-c1">%d</span> CPU(s)<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, runtime.<span class="pl-smi">GOOS</span>, runtime.<span class="pl-smi">GOARCH</span>, runtime.<span class="pl-c1">NumCPU</span>())</td>
-      </tr>
-      <tr>
-        <td id="L63" class="blob-num js-line-number" data-line-number="63"></td>
-        <td id="LC63" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> runtime.<span class="pl-smi">GOARCH</span> != <span class="pl-s"><span class="pl-pds">&quot;</span>arm<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L64" class="blob-num js-line-number" data-line-number="64"></td>
-        <td id="LC64" class="blob-code blob-code-inner js-file-line">		<span class="pl-smi">u</span>, <span class="pl-smi">err</span> <span class="pl-k">:=</span> user.<span class="pl-c1">Current</span>()</td>
-      </tr>
-      <tr>
-        <td id="L65" class="blob-num js-line-number" data-line-number="65"></td>
-        <td id="LC65" class="blob-code blob-code-inner js-file-line">		<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L66" class="blob-num js-line-number" data-line-number="66"></td>
-        <td id="LC66" class="blob-code blob-code-inner js-file-line">			<span class="pl-c1">panic</span>(err.<span class="pl-c1">Error</span>())</td>
-      </tr>
-      <tr>
-        <td id="L67" class="blob-num js-line-number" data-line-number="67"></td>
-        <td id="LC67" class="blob-code blob-code-inner js-file-line">		}</td>
-      </tr>
-      <tr>
-        <td id="L68" class="blob-num js-line-number" data-line-number="68"></td>
-        <td id="LC68" class="blob-code blob-code-inner js-file-line">		log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>          Username: <span class="pl-c1">%s</span> (<span class="pl-c1">%s</span>) <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, u.<span class="pl-smi">Name</span>, u.<span class="pl-smi">Username</span>, u.<span class="pl-smi">HomeDir</span>)</td>
-      </tr>
-      <tr>
-        <td id="L69" class="blob-num js-line-number" data-line-number="69"></td>
-        <td id="LC69" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L70" class="blob-num js-line-number" data-line-number="70"></td>
-        <td id="LC70" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>              IPV4: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, itr.<span class="pl-smi">Ipv4</span>)</td>
-      </tr>
-      <tr>
-        <td id="L71" class="blob-num js-line-number" data-line-number="71"></td>
-        <td id="LC71" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>log.Printf(&quot;              Mask: %d\n&quot;, itr.Mask)</span></td>
-      </tr>
-      <tr>
-        <td id="L72" class="blob-num js-line-number" data-line-number="72"></td>
-        <td id="LC72" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>log.Printf(&quot;           Network: %v\n&quot;, itr.Network)</span></td>
-      </tr>
-      <tr>
-        <td id="L73" class="blob-num js-line-number" data-line-number="73"></td>
-        <td id="LC73" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>              IPV6: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, itr.<span class="pl-smi">Ipv6</span>)</td>
-      </tr>
-      <tr>
-        <td id="L74" class="blob-num js-line-number" data-line-number="74"></td>
-        <td id="LC74" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L75" class="blob-num js-line-number" data-line-number="75"></td>
-        <td id="LC75" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L76" class="blob-num js-line-number" data-line-number="76"></td>
-        <td id="LC76" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">Listflags</span>(<span class="pl-v">fg</span> <span class="pl-v">flagsettings</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L77" class="blob-num js-line-number" data-line-number="77"></td>
-        <td id="LC77" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    Saved Settings: <span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L78" class="blob-num js-line-number" data-line-number="78"></td>
-        <td id="LC78" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>         Interface: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">Intface</span>)</td>
-      </tr>
-      <tr>
-        <td id="L79" class="blob-num js-line-number" data-line-number="79"></td>
-        <td id="LC79" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>             Index: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">Index</span>)</td>
-      </tr>
-      <tr>
-        <td id="L80" class="blob-num js-line-number" data-line-number="80"></td>
-        <td id="LC80" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>          Filename: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">Filename</span>)</td>
-      </tr>
-      <tr>
-        <td id="L81" class="blob-num js-line-number" data-line-number="81"></td>
-        <td id="LC81" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>      Selected MAC: (<span class="pl-c1">%v</span>)<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">SelectMAC</span>)</td>
-      </tr>
-      <tr>
-        <td id="L82" class="blob-num js-line-number" data-line-number="82"></td>
-        <td id="LC82" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>            SetRBF: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">SetRBF</span>)</td>
-      </tr>
-      <tr>
-        <td id="L83" class="blob-num js-line-number" data-line-number="83"></td>
-        <td id="LC83" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>             Debug: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">Debug</span>)</td>
-      </tr>
-      <tr>
-        <td id="L84" class="blob-num js-line-number" data-line-number="84"></td>
-        <td id="LC84" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>            Ddelay: <span class="pl-c1">%d</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">Ddelay</span>)</td>
-      </tr>
-      <tr>
-        <td id="L85" class="blob-num js-line-number" data-line-number="85"></td>
-        <td id="LC85" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>            Edelay: <span class="pl-c1">%d</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">Edelay</span>)</td>
-      </tr>
-      <tr>
-        <td id="L86" class="blob-num js-line-number" data-line-number="86"></td>
-        <td id="LC86" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L87" class="blob-num js-line-number" data-line-number="87"></td>
-        <td id="LC87" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L88" class="blob-num js-line-number" data-line-number="88"></td>
-        <td id="LC88" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">Listflagstemp</span>(<span class="pl-v">fgt</span> <span class="pl-v">flagtemp</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L89" class="blob-num js-line-number" data-line-number="89"></td>
-        <td id="LC89" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>     Temp settings: <span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L90" class="blob-num js-line-number" data-line-number="90"></td>
-        <td id="LC90" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>          Settings: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fgt.<span class="pl-smi">Settings</span>)</td>
-      </tr>
-      <tr>
-        <td id="L91" class="blob-num js-line-number" data-line-number="91"></td>
-        <td id="LC91" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>             SetIP: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fgt.<span class="pl-smi">SetIP</span>)</td>
-      </tr>
-      <tr>
-        <td id="L92" class="blob-num js-line-number" data-line-number="92"></td>
-        <td id="LC92" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>              Save: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fgt.<span class="pl-smi">Save</span>)</td>
-      </tr>
-      <tr>
-        <td id="L93" class="blob-num js-line-number" data-line-number="93"></td>
-        <td id="LC93" class="blob-code blob-code-inner js-file-line">	log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>              Load: <span class="pl-c1">%v</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fgt.<span class="pl-smi">Load</span>)</td>
-      </tr>
-      <tr>
-        <td id="L94" class="blob-num js-line-number" data-line-number="94"></td>
-        <td id="LC94" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L95" class="blob-num js-line-number" data-line-number="95"></td>
-        <td id="LC95" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L96" class="blob-num js-line-number" data-line-number="96"></td>
-        <td id="LC96" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">Initflags</span>(<span class="pl-v">fg</span> *<span class="pl-v">flagsettings</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L97" class="blob-num js-line-number" data-line-number="97"></td>
-        <td id="LC97" class="blob-code blob-code-inner js-file-line">	fg.<span class="pl-smi">Intface</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L98" class="blob-num js-line-number" data-line-number="98"></td>
-        <td id="LC98" class="blob-code blob-code-inner js-file-line">	fg.<span class="pl-smi">Filename</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L99" class="blob-num js-line-number" data-line-number="99"></td>
-        <td id="LC99" class="blob-code blob-code-inner js-file-line">	fg.<span class="pl-smi">SelectMAC</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L100" class="blob-num js-line-number" data-line-number="100"></td>
-        <td id="LC100" class="blob-code blob-code-inner js-file-line">	fg.<span class="pl-smi">SetRBF</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L101" class="blob-num js-line-number" data-line-number="101"></td>
-        <td id="LC101" class="blob-code blob-code-inner js-file-line">	fg.<span class="pl-smi">Debug</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L102" class="blob-num js-line-number" data-line-number="102"></td>
-        <td id="LC102" class="blob-code blob-code-inner js-file-line">	fg.<span class="pl-smi">Ddelay</span> = <span class="pl-c1">2</span></td>
-      </tr>
-      <tr>
-        <td id="L103" class="blob-num js-line-number" data-line-number="103"></td>
-        <td id="LC103" class="blob-code blob-code-inner js-file-line">	fg.<span class="pl-smi">Edelay</span> = <span class="pl-c1">60</span></td>
-      </tr>
-      <tr>
-        <td id="L104" class="blob-num js-line-number" data-line-number="104"></td>
-        <td id="LC104" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L105" class="blob-num js-line-number" data-line-number="105"></td>
-        <td id="LC105" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L106" class="blob-num js-line-number" data-line-number="106"></td>
-        <td id="LC106" class="blob-code blob-code-inner js-file-line"><span class="pl-k">type</span> flagsettings <span class="pl-k">struct</span> {</td>
-      </tr>
-      <tr>
-        <td id="L107" class="blob-num js-line-number" data-line-number="107"></td>
-        <td id="LC107" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Filename</span>  <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L108" class="blob-num js-line-number" data-line-number="108"></td>
-        <td id="LC108" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Intface</span>   <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L109" class="blob-num js-line-number" data-line-number="109"></td>
-        <td id="LC109" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Index</span>     <span class="pl-k">int</span></td>
-      </tr>
-      <tr>
-        <td id="L110" class="blob-num js-line-number" data-line-number="110"></td>
-        <td id="LC110" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">SelectMAC</span> <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L111" class="blob-num js-line-number" data-line-number="111"></td>
-        <td id="LC111" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">SetRBF</span>    <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L112" class="blob-num js-line-number" data-line-number="112"></td>
-        <td id="LC112" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Debug</span>     <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L113" class="blob-num js-line-number" data-line-number="113"></td>
-        <td id="LC113" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Ddelay</span>    <span class="pl-k">int</span></td>
-      </tr>
-      <tr>
-        <td id="L114" class="blob-num js-line-number" data-line-number="114"></td>
-        <td id="LC114" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Edelay</span>    <span class="pl-k">int</span></td>
-      </tr>
-      <tr>
-        <td id="L115" class="blob-num js-line-number" data-line-number="115"></td>
-        <td id="LC115" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L116" class="blob-num js-line-number" data-line-number="116"></td>
-        <td id="LC116" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L117" class="blob-num js-line-number" data-line-number="117"></td>
-        <td id="LC117" class="blob-code blob-code-inner js-file-line"><span class="pl-k">type</span> flagtemp <span class="pl-k">struct</span> {</td>
-      </tr>
-      <tr>
-        <td id="L118" class="blob-num js-line-number" data-line-number="118"></td>
-        <td id="LC118" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">SetIP</span>    <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L119" class="blob-num js-line-number" data-line-number="119"></td>
-        <td id="LC119" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Settings</span> <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L120" class="blob-num js-line-number" data-line-number="120"></td>
-        <td id="LC120" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Save</span>     <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L121" class="blob-num js-line-number" data-line-number="121"></td>
-        <td id="LC121" class="blob-code blob-code-inner js-file-line">	<span class="pl-v">Load</span>     <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L122" class="blob-num js-line-number" data-line-number="122"></td>
-        <td id="LC122" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L123" class="blob-num js-line-number" data-line-number="123"></td>
-        <td id="LC123" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L124" class="blob-num js-line-number" data-line-number="124"></td>
-        <td id="LC124" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">Initflagstemp</span>(<span class="pl-v">fgt</span> *<span class="pl-v">flagtemp</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L125" class="blob-num js-line-number" data-line-number="125"></td>
-        <td id="LC125" class="blob-code blob-code-inner js-file-line">	fgt.<span class="pl-smi">SetIP</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L126" class="blob-num js-line-number" data-line-number="126"></td>
-        <td id="LC126" class="blob-code blob-code-inner js-file-line">	fgt.<span class="pl-smi">Settings</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L127" class="blob-num js-line-number" data-line-number="127"></td>
-        <td id="LC127" class="blob-code blob-code-inner js-file-line">	fgt.<span class="pl-smi">Save</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L128" class="blob-num js-line-number" data-line-number="128"></td>
-        <td id="LC128" class="blob-code blob-code-inner js-file-line">	fgt.<span class="pl-smi">Load</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L129" class="blob-num js-line-number" data-line-number="129"></td>
-        <td id="LC129" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L130" class="blob-num js-line-number" data-line-number="130"></td>
-        <td id="LC130" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L131" class="blob-num js-line-number" data-line-number="131"></td>
-        <td id="LC131" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">Parseflagstruct</span>(<span class="pl-v">fg</span> *<span class="pl-v">flagsettings</span>, <span class="pl-v">fgt</span> *<span class="pl-v">flagtemp</span>, <span class="pl-v">id</span> <span class="pl-v">int</span>, <span class="pl-v">stmac</span> <span class="pl-v">string</span>, <span class="pl-v">stip</span> <span class="pl-v">string</span>, <span class="pl-v">strbf</span> <span class="pl-v">string</span>, <span class="pl-v">db</span> <span class="pl-v">string</span>, <span class="pl-v">ss</span> <span class="pl-v">string</span>, <span class="pl-v">sv</span> <span class="pl-v">string</span>, <span class="pl-v">ld</span> <span class="pl-v">string</span>, <span class="pl-v">dd</span> <span class="pl-v">int</span>, <span class="pl-v">ed</span> <span class="pl-v">int</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L132" class="blob-num js-line-number" data-line-number="132"></td>
-        <td id="LC132" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L133" class="blob-num js-line-number" data-line-number="133"></td>
-        <td id="LC133" class="blob-code blob-code-inner js-file-line">	<span class="pl-c1">Initflags</span>(fg)</td>
-      </tr>
-      <tr>
-        <td id="L134" class="blob-num js-line-number" data-line-number="134"></td>
-        <td id="LC134" class="blob-code blob-code-inner js-file-line">	<span class="pl-c1">Initflagstemp</span>(fgt)</td>
-      </tr>
-      <tr>
-        <td id="L135" class="blob-num js-line-number" data-line-number="135"></td>
-        <td id="LC135" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L136" class="blob-num js-line-number" data-line-number="136"></td>
-        <td id="LC136" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> (ld == <span class="pl-s"><span class="pl-pds">&quot;</span>default<span class="pl-pds">&quot;</span></span>) || (ld == <span class="pl-s"><span class="pl-pds">&quot;</span>Default<span class="pl-pds">&quot;</span></span>) {</td>
-      </tr>
-      <tr>
-        <td id="L137" class="blob-num js-line-number" data-line-number="137"></td>
-        <td id="LC137" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Filename</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>HPSDRProgrammer_cmd.json<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L138" class="blob-num js-line-number" data-line-number="138"></td>
-        <td id="LC138" class="blob-code blob-code-inner js-file-line">	} <span class="pl-k">else</span> <span class="pl-k">if</span> ld != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L139" class="blob-num js-line-number" data-line-number="139"></td>
-        <td id="LC139" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Filename</span> = ld</td>
-      </tr>
-      <tr>
-        <td id="L140" class="blob-num js-line-number" data-line-number="140"></td>
-        <td id="LC140" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L141" class="blob-num js-line-number" data-line-number="141"></td>
-        <td id="LC141" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L142" class="blob-num js-line-number" data-line-number="142"></td>
-        <td id="LC142" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> ld != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L143" class="blob-num js-line-number" data-line-number="143"></td>
-        <td id="LC143" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L144" class="blob-num js-line-number" data-line-number="144"></td>
-        <td id="LC144" class="blob-code blob-code-inner js-file-line">		<span class="pl-smi">dta</span>, <span class="pl-smi">_</span> <span class="pl-k">:=</span> ioutil.<span class="pl-c1">ReadFile</span>(fg.<span class="pl-smi">Filename</span>)</td>
-      </tr>
-      <tr>
-        <td id="L145" class="blob-num js-line-number" data-line-number="145"></td>
-        <td id="LC145" class="blob-code blob-code-inner js-file-line">		<span class="pl-smi">err</span> <span class="pl-k">:=</span> json.<span class="pl-c1">Unmarshal</span>(dta, &amp;fg)</td>
-      </tr>
-      <tr>
-        <td id="L146" class="blob-num js-line-number" data-line-number="146"></td>
-        <td id="LC146" class="blob-code blob-code-inner js-file-line">		<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L147" class="blob-num js-line-number" data-line-number="147"></td>
-        <td id="LC147" class="blob-code blob-code-inner js-file-line">			log.<span class="pl-c1">Println</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>error:<span class="pl-pds">&quot;</span></span>, err)</td>
-      </tr>
-      <tr>
-        <td id="L148" class="blob-num js-line-number" data-line-number="148"></td>
-        <td id="LC148" class="blob-code blob-code-inner js-file-line">		}</td>
-      </tr>
-      <tr>
-        <td id="L149" class="blob-num js-line-number" data-line-number="149"></td>
-        <td id="LC149" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L150" class="blob-num js-line-number" data-line-number="150"></td>
-        <td id="LC150" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L151" class="blob-num js-line-number" data-line-number="151"></td>
-        <td id="LC151" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>if ifn != &quot;none&quot; {</span></td>
-      </tr>
-      <tr>
-        <td id="L152" class="blob-num js-line-number" data-line-number="152"></td>
-        <td id="LC152" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>	fg.Intface = ifn</span></td>
-      </tr>
-      <tr>
-        <td id="L153" class="blob-num js-line-number" data-line-number="153"></td>
-        <td id="LC153" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>}</span></td>
-      </tr>
-      <tr>
-        <td id="L154" class="blob-num js-line-number" data-line-number="154"></td>
-        <td id="LC154" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> id != <span class="pl-c1">0</span> {</td>
-      </tr>
-      <tr>
-        <td id="L155" class="blob-num js-line-number" data-line-number="155"></td>
-        <td id="LC155" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Index</span> = id</td>
-      </tr>
-      <tr>
-        <td id="L156" class="blob-num js-line-number" data-line-number="156"></td>
-        <td id="LC156" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L157" class="blob-num js-line-number" data-line-number="157"></td>
-        <td id="LC157" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> stmac != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L158" class="blob-num js-line-number" data-line-number="158"></td>
-        <td id="LC158" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">SelectMAC</span> = stmac</td>
-      </tr>
-      <tr>
-        <td id="L159" class="blob-num js-line-number" data-line-number="159"></td>
-        <td id="LC159" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L160" class="blob-num js-line-number" data-line-number="160"></td>
-        <td id="LC160" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> strbf != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L161" class="blob-num js-line-number" data-line-number="161"></td>
-        <td id="LC161" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">SetRBF</span> = strbf</td>
-      </tr>
-      <tr>
-        <td id="L162" class="blob-num js-line-number" data-line-number="162"></td>
-        <td id="LC162" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L163" class="blob-num js-line-number" data-line-number="163"></td>
-        <td id="LC163" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> db != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L164" class="blob-num js-line-number" data-line-number="164"></td>
-        <td id="LC164" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Debug</span> = db</td>
-      </tr>
-      <tr>
-        <td id="L165" class="blob-num js-line-number" data-line-number="165"></td>
-        <td id="LC165" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L166" class="blob-num js-line-number" data-line-number="166"></td>
-        <td id="LC166" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> ed != <span class="pl-c1">20</span> {</td>
-      </tr>
-      <tr>
-        <td id="L167" class="blob-num js-line-number" data-line-number="167"></td>
-        <td id="LC167" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Edelay</span> = ed</td>
-      </tr>
-      <tr>
-        <td id="L168" class="blob-num js-line-number" data-line-number="168"></td>
-        <td id="LC168" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L169" class="blob-num js-line-number" data-line-number="169"></td>
-        <td id="LC169" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> dd != <span class="pl-c1">2</span> {</td>
-      </tr>
-      <tr>
-        <td id="L170" class="blob-num js-line-number" data-line-number="170"></td>
-        <td id="LC170" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Ddelay</span> = dd</td>
-      </tr>
-      <tr>
-        <td id="L171" class="blob-num js-line-number" data-line-number="171"></td>
-        <td id="LC171" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L172" class="blob-num js-line-number" data-line-number="172"></td>
-        <td id="LC172" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> ed != <span class="pl-c1">2</span> {</td>
-      </tr>
-      <tr>
-        <td id="L173" class="blob-num js-line-number" data-line-number="173"></td>
-        <td id="LC173" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Edelay</span> = ed</td>
-      </tr>
-      <tr>
-        <td id="L174" class="blob-num js-line-number" data-line-number="174"></td>
-        <td id="LC174" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L175" class="blob-num js-line-number" data-line-number="175"></td>
-        <td id="LC175" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> stip != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L176" class="blob-num js-line-number" data-line-number="176"></td>
-        <td id="LC176" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">SetIP</span> = stip</td>
-      </tr>
-      <tr>
-        <td id="L177" class="blob-num js-line-number" data-line-number="177"></td>
-        <td id="LC177" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L178" class="blob-num js-line-number" data-line-number="178"></td>
-        <td id="LC178" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> ss != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L179" class="blob-num js-line-number" data-line-number="179"></td>
-        <td id="LC179" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">Settings</span> = ss</td>
-      </tr>
-      <tr>
-        <td id="L180" class="blob-num js-line-number" data-line-number="180"></td>
-        <td id="LC180" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L181" class="blob-num js-line-number" data-line-number="181"></td>
-        <td id="LC181" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> sv == <span class="pl-s"><span class="pl-pds">&quot;</span>default<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L182" class="blob-num js-line-number" data-line-number="182"></td>
-        <td id="LC182" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">Save</span> = sv</td>
-      </tr>
-      <tr>
-        <td id="L183" class="blob-num js-line-number" data-line-number="183"></td>
-        <td id="LC183" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Filename</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>HPSDRProgrammer_cmd.json<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L184" class="blob-num js-line-number" data-line-number="184"></td>
-        <td id="LC184" class="blob-code blob-code-inner js-file-line">	} <span class="pl-k">else</span> <span class="pl-k">if</span> sv != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L185" class="blob-num js-line-number" data-line-number="185"></td>
-        <td id="LC185" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Filename</span> = sv</td>
-      </tr>
-      <tr>
-        <td id="L186" class="blob-num js-line-number" data-line-number="186"></td>
-        <td id="LC186" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">Save</span> = sv</td>
-      </tr>
-      <tr>
-        <td id="L187" class="blob-num js-line-number" data-line-number="187"></td>
-        <td id="LC187" class="blob-code blob-code-inner js-file-line">	} <span class="pl-k">else</span> {</td>
-      </tr>
-      <tr>
-        <td id="L188" class="blob-num js-line-number" data-line-number="188"></td>
-        <td id="LC188" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">Save</span> = sv</td>
-      </tr>
-      <tr>
-        <td id="L189" class="blob-num js-line-number" data-line-number="189"></td>
-        <td id="LC189" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L190" class="blob-num js-line-number" data-line-number="190"></td>
-        <td id="LC190" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> ld == <span class="pl-s"><span class="pl-pds">&quot;</span>default<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L191" class="blob-num js-line-number" data-line-number="191"></td>
-        <td id="LC191" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">Load</span> = ld</td>
-      </tr>
-      <tr>
-        <td id="L192" class="blob-num js-line-number" data-line-number="192"></td>
-        <td id="LC192" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Filename</span> = <span class="pl-s"><span class="pl-pds">&quot;</span>HPSDRProgrammer_cmd.json<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L193" class="blob-num js-line-number" data-line-number="193"></td>
-        <td id="LC193" class="blob-code blob-code-inner js-file-line">	} <span class="pl-k">else</span> <span class="pl-k">if</span> ld != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L194" class="blob-num js-line-number" data-line-number="194"></td>
-        <td id="LC194" class="blob-code blob-code-inner js-file-line">		fg.<span class="pl-smi">Filename</span> = ld</td>
-      </tr>
-      <tr>
-        <td id="L195" class="blob-num js-line-number" data-line-number="195"></td>
-        <td id="LC195" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">Load</span> = ld</td>
-      </tr>
-      <tr>
-        <td id="L196" class="blob-num js-line-number" data-line-number="196"></td>
-        <td id="LC196" class="blob-code blob-code-inner js-file-line">	} <span class="pl-k">else</span> {</td>
-      </tr>
-      <tr>
-        <td id="L197" class="blob-num js-line-number" data-line-number="197"></td>
-        <td id="LC197" class="blob-code blob-code-inner js-file-line">		fgt.<span class="pl-smi">Load</span> = ld</td>
-      </tr>
-      <tr>
-        <td id="L198" class="blob-num js-line-number" data-line-number="198"></td>
-        <td id="LC198" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L199" class="blob-num js-line-number" data-line-number="199"></td>
-        <td id="LC199" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L200" class="blob-num js-line-number" data-line-number="200"></td>
-        <td id="LC200" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> fgt.<span class="pl-smi">Save</span> != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L201" class="blob-num js-line-number" data-line-number="201"></td>
-        <td id="LC201" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L202" class="blob-num js-line-number" data-line-number="202"></td>
-        <td id="LC202" class="blob-code blob-code-inner js-file-line">		<span class="pl-smi">f</span>, <span class="pl-smi">err</span> <span class="pl-k">:=</span> os.<span class="pl-c1">Create</span>(fg.<span class="pl-smi">Filename</span>)</td>
-      </tr>
-      <tr>
-        <td id="L203" class="blob-num js-line-number" data-line-number="203"></td>
-        <td id="LC203" class="blob-code blob-code-inner js-file-line">		<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L204" class="blob-num js-line-number" data-line-number="204"></td>
-        <td id="LC204" class="blob-code blob-code-inner js-file-line">			<span class="pl-c1">panic</span>(err)</td>
-      </tr>
-      <tr>
-        <td id="L205" class="blob-num js-line-number" data-line-number="205"></td>
-        <td id="LC205" class="blob-code blob-code-inner js-file-line">		}</td>
-      </tr>
-      <tr>
-        <td id="L206" class="blob-num js-line-number" data-line-number="206"></td>
-        <td id="LC206" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L207" class="blob-num js-line-number" data-line-number="207"></td>
-        <td id="LC207" class="blob-code blob-code-inner js-file-line">		<span class="pl-smi">b</span>, <span class="pl-smi">err</span> <span class="pl-k">:=</span> json.<span class="pl-c1">MarshalIndent</span>(fg, <span class="pl-s"><span class="pl-pds">&quot;</span><span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span><span class="pl-cce">\t</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L208" class="blob-num js-line-number" data-line-number="208"></td>
-        <td id="LC208" class="blob-code blob-code-inner js-file-line">		<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L209" class="blob-num js-line-number" data-line-number="209"></td>
-        <td id="LC209" class="blob-code blob-code-inner js-file-line">			log.<span class="pl-c1">Println</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>error:<span class="pl-pds">&quot;</span></span>, err)</td>
-      </tr>
-      <tr>
-        <td id="L210" class="blob-num js-line-number" data-line-number="210"></td>
-        <td id="LC210" class="blob-code blob-code-inner js-file-line">		}</td>
-      </tr>
-      <tr>
-        <td id="L211" class="blob-num js-line-number" data-line-number="211"></td>
-        <td id="LC211" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L212" class="blob-num js-line-number" data-line-number="212"></td>
-        <td id="LC212" class="blob-code blob-code-inner js-file-line">		fmt.<span class="pl-c1">Fprintf</span>(f, <span class="pl-s"><span class="pl-pds">&quot;</span><span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, b)</td>
-      </tr>
-      <tr>
-        <td id="L213" class="blob-num js-line-number" data-line-number="213"></td>
-        <td id="LC213" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L214" class="blob-num js-line-number" data-line-number="214"></td>
-        <td id="LC214" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L215" class="blob-num js-line-number" data-line-number="215"></td>
-        <td id="LC215" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> ss != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L216" class="blob-num js-line-number" data-line-number="216"></td>
-        <td id="LC216" class="blob-code blob-code-inner js-file-line">		<span class="pl-c1">Listflags</span>(*fg)</td>
-      </tr>
-      <tr>
-        <td id="L217" class="blob-num js-line-number" data-line-number="217"></td>
-        <td id="LC217" class="blob-code blob-code-inner js-file-line">		<span class="pl-c1">Listflagstemp</span>(*fgt)</td>
-      </tr>
-      <tr>
-        <td id="L218" class="blob-num js-line-number" data-line-number="218"></td>
-        <td id="LC218" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L219" class="blob-num js-line-number" data-line-number="219"></td>
-        <td id="LC219" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L220" class="blob-num js-line-number" data-line-number="220"></td>
-        <td id="LC220" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-      <tr>
-        <td id="L221" class="blob-num js-line-number" data-line-number="221"></td>
-        <td id="LC221" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L222" class="blob-num js-line-number" data-line-number="222"></td>
-        <td id="LC222" class="blob-code blob-code-inner js-file-line"><span class="pl-k">func</span> <span class="pl-en">main</span>() {</td>
-      </tr>
-      <tr>
-        <td id="L223" class="blob-num js-line-number" data-line-number="223"></td>
-        <td id="LC223" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">var</span> <span class="pl-smi">fg</span> flagsettings</td>
-      </tr>
-      <tr>
-        <td id="L224" class="blob-num js-line-number" data-line-number="224"></td>
-        <td id="LC224" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">var</span> <span class="pl-smi">fgt</span> flagtemp</td>
-      </tr>
-      <tr>
-        <td id="L225" class="blob-num js-line-number" data-line-number="225"></td>
-        <td id="LC225" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>var erstat newopenhpsdr.Erasestatus</span></td>
-      </tr>
-      <tr>
-        <td id="L226" class="blob-num js-line-number" data-line-number="226"></td>
-        <td id="LC226" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L227" class="blob-num js-line-number" data-line-number="227"></td>
-        <td id="LC227" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span> Create the command line flags</span></td>
-      </tr>
-      <tr>
-        <td id="L228" class="blob-num js-line-number" data-line-number="228"></td>
-        <td id="LC228" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>ifn := flag.String(&quot;interface&quot;, &quot;none&quot;, &quot;Select one interface number&quot;)</span></td>
-      </tr>
-      <tr>
-        <td id="L229" class="blob-num js-line-number" data-line-number="229"></td>
-        <td id="LC229" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">id</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">Int</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>index<span class="pl-pds">&quot;</span></span>, <span class="pl-c1">0</span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Select one interface by number<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L230" class="blob-num js-line-number" data-line-number="230"></td>
-        <td id="LC230" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">stmac</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">String</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>selectMAC<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Select Board by MAC address<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L231" class="blob-num js-line-number" data-line-number="231"></td>
-        <td id="LC231" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">stip</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">String</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>setIP<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Set IP address, unused number from your subnet or 0.0.0.0 for DHCP<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L232" class="blob-num js-line-number" data-line-number="232"></td>
-        <td id="LC232" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">strbf</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">String</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>setRBF<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Select the RBF file to write to the board<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L233" class="blob-num js-line-number" data-line-number="233"></td>
-        <td id="LC233" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">dd</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">Int</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>ddelay<span class="pl-pds">&quot;</span></span>, <span class="pl-c1">8</span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Discovery delay before a rediscovery<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L234" class="blob-num js-line-number" data-line-number="234"></td>
-        <td id="LC234" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">ed</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">Int</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>edelay<span class="pl-pds">&quot;</span></span>, <span class="pl-c1">60</span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Discovery delay before a rediscovery<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L235" class="blob-num js-line-number" data-line-number="235"></td>
-        <td id="LC235" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">db</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">String</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>debug<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Turn debugging and output type, (none, dec, hex)<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L236" class="blob-num js-line-number" data-line-number="236"></td>
-        <td id="LC236" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">ss</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">String</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>settings<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Show the settings values (show)<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L237" class="blob-num js-line-number" data-line-number="237"></td>
-        <td id="LC237" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">sv</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">String</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>save<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Save these current flags for future use in default or a named file<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L238" class="blob-num js-line-number" data-line-number="238"></td>
-        <td id="LC238" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">ld</span> <span class="pl-k">:=</span> flag.<span class="pl-c1">String</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>load<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>, <span class="pl-s"><span class="pl-pds">&quot;</span>Load a saved command file from default or a named file<span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L239" class="blob-num js-line-number" data-line-number="239"></td>
-        <td id="LC239" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>cadr := flag.Bool(&quot;checkaddress&quot;, true, &quot;check if new address is in subdomain and not restricted space&quot;)</span></td>
-      </tr>
-      <tr>
-        <td id="L240" class="blob-num js-line-number" data-line-number="240"></td>
-        <td id="LC240" class="blob-code blob-code-inner js-file-line">	<span class="pl-c"><span class="pl-c">//</span>cbad := flag.Bool(&quot;checkboard&quot;, true, &quot;check if new RBF file name has the same name as the board type&quot;)</span></td>
-      </tr>
-      <tr>
-        <td id="L241" class="blob-num js-line-number" data-line-number="241"></td>
-        <td id="LC241" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L242" class="blob-num js-line-number" data-line-number="242"></td>
-        <td id="LC242" class="blob-code blob-code-inner js-file-line">	flag.<span class="pl-c1">Parse</span>()</td>
-      </tr>
-      <tr>
-        <td id="L243" class="blob-num js-line-number" data-line-number="243"></td>
-        <td id="LC243" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L244" class="blob-num js-line-number" data-line-number="244"></td>
-        <td id="LC244" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">if</span> flag.<span class="pl-c1">NFlag</span>() &lt; <span class="pl-c1">1</span> {</td>
-      </tr>
-      <tr>
-        <td id="L245" class="blob-num js-line-number" data-line-number="245"></td>
-        <td id="LC245" class="blob-code blob-code-inner js-file-line">		<span class="pl-c1">program</span>()</td>
-      </tr>
-      <tr>
-        <td id="L246" class="blob-num js-line-number" data-line-number="246"></td>
-        <td id="LC246" class="blob-code blob-code-inner js-file-line">		<span class="pl-c1">usage</span>()</td>
-      </tr>
-      <tr>
-        <td id="L247" class="blob-num js-line-number" data-line-number="247"></td>
-        <td id="LC247" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L248" class="blob-num js-line-number" data-line-number="248"></td>
-        <td id="LC248" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L249" class="blob-num js-line-number" data-line-number="249"></td>
-        <td id="LC249" class="blob-code blob-code-inner js-file-line">	<span class="pl-c1">Parseflagstruct</span>(&amp;fg, &amp;fgt, *id, *stmac, *stip, *strbf, *db, *ss, *sv, *ld, *dd, *ed)</td>
-      </tr>
-      <tr>
-        <td id="L250" class="blob-num js-line-number" data-line-number="250"></td>
-        <td id="LC250" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L251" class="blob-num js-line-number" data-line-number="251"></td>
-        <td id="LC251" class="blob-code blob-code-inner js-file-line">	<span class="pl-smi">intf</span> <span class="pl-k">:=</span> newopenhpsdr.<span class="pl-c1">Interfaces</span>()</td>
-      </tr>
-      <tr>
-        <td id="L252" class="blob-num js-line-number" data-line-number="252"></td>
-        <td id="LC252" class="blob-code blob-code-inner js-file-line">	<span class="pl-k">for</span> <span class="pl-smi">i</span> <span class="pl-k">:=</span> <span class="pl-k">range</span> intf {</td>
-      </tr>
-      <tr>
-        <td id="L253" class="blob-num js-line-number" data-line-number="253"></td>
-        <td id="LC253" class="blob-code blob-code-inner js-file-line">		<span class="pl-k">if</span> flag.<span class="pl-c1">NFlag</span>() &lt; <span class="pl-c1">1</span> {</td>
-      </tr>
-      <tr>
-        <td id="L254" class="blob-num js-line-number" data-line-number="254"></td>
-        <td id="LC254" class="blob-code blob-code-inner js-file-line">			<span class="pl-c"><span class="pl-c">//</span> if no flags list the interfaces in short form</span></td>
-      </tr>
-      <tr>
-        <td id="L255" class="blob-num js-line-number" data-line-number="255"></td>
-        <td id="LC255" class="blob-code blob-code-inner js-file-line">			log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    <span class="pl-c1">%d</span> - <span class="pl-c1">%s</span> (<span class="pl-c1">%s</span>)<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, intf[i].<span class="pl-smi">Index</span>, intf[i].<span class="pl-smi">Intname</span>, intf[i].<span class="pl-smi">MAC</span>)</td>
-      </tr>
-      <tr>
-        <td id="L256" class="blob-num js-line-number" data-line-number="256"></td>
-        <td id="LC256" class="blob-code blob-code-inner js-file-line">		} <span class="pl-k">else</span> <span class="pl-k">if</span> (flag.<span class="pl-c1">NFlag</span>() == <span class="pl-c1">1</span>) &amp;&amp; (fg.<span class="pl-smi">Index</span> == <span class="pl-c1">0</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L257" class="blob-num js-line-number" data-line-number="257"></td>
-        <td id="LC257" class="blob-code blob-code-inner js-file-line">			<span class="pl-k">if</span> fg.<span class="pl-smi">Debug</span> == <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L258" class="blob-num js-line-number" data-line-number="258"></td>
-        <td id="LC258" class="blob-code blob-code-inner js-file-line">				<span class="pl-c"><span class="pl-c">//</span> if one flag and it is debug = none, list the interface in short form</span></td>
-      </tr>
-      <tr>
-        <td id="L259" class="blob-num js-line-number" data-line-number="259"></td>
-        <td id="LC259" class="blob-code blob-code-inner js-file-line">				log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    <span class="pl-c1">%d</span> - <span class="pl-c1">%s</span> (<span class="pl-c1">%s</span>)<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, intf[i].<span class="pl-smi">Index</span>, intf[i].<span class="pl-smi">Intname</span>, intf[i].<span class="pl-smi">MAC</span>)</td>
-      </tr>
-      <tr>
-        <td id="L260" class="blob-num js-line-number" data-line-number="260"></td>
-        <td id="LC260" class="blob-code blob-code-inner js-file-line">			} <span class="pl-k">else</span> {</td>
-      </tr>
-      <tr>
-        <td id="L261" class="blob-num js-line-number" data-line-number="261"></td>
-        <td id="LC261" class="blob-code blob-code-inner js-file-line">				<span class="pl-c"><span class="pl-c">//</span> if one flag and it is debug = dec or hex, list the interface in long form</span></td>
-      </tr>
-      <tr>
-        <td id="L262" class="blob-num js-line-number" data-line-number="262"></td>
-        <td id="LC262" class="blob-code blob-code-inner js-file-line">				log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>    <span class="pl-c1">%d</span> - <span class="pl-c1">%s</span> (<span class="pl-c1">%s</span> <span class="pl-c1">%s</span>  <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, intf[i].<span class="pl-smi">Index</span>, intf[i].<span class="pl-smi">Intname</span>, intf[i].<span class="pl-smi">MAC</span>, intf[i].<span class="pl-smi">Ipv4</span>, intf[i].<span class="pl-smi">Ipv6</span>)</td>
-      </tr>
-      <tr>
-        <td id="L263" class="blob-num js-line-number" data-line-number="263"></td>
-        <td id="LC263" class="blob-code blob-code-inner js-file-line">			}</td>
-      </tr>
-      <tr>
-        <td id="L264" class="blob-num js-line-number" data-line-number="264"></td>
-        <td id="LC264" class="blob-code blob-code-inner js-file-line">		}</td>
-      </tr>
-      <tr>
-        <td id="L265" class="blob-num js-line-number" data-line-number="265"></td>
-        <td id="LC265" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L266" class="blob-num js-line-number" data-line-number="266"></td>
-        <td id="LC266" class="blob-code blob-code-inner js-file-line">		<span class="pl-c"><span class="pl-c">//</span> if ifn flag matches the current interface</span></td>
-      </tr>
-      <tr>
-        <td id="L267" class="blob-num js-line-number" data-line-number="267"></td>
-        <td id="LC267" class="blob-code blob-code-inner js-file-line">		<span class="pl-k">if</span> fg.<span class="pl-smi">Index</span> == intf[i].<span class="pl-smi">Index</span> {</td>
-      </tr>
-      <tr>
-        <td id="L268" class="blob-num js-line-number" data-line-number="268"></td>
-        <td id="LC268" class="blob-code blob-code-inner js-file-line">			<span class="pl-k">if</span> <span class="pl-c1">len</span>(intf[i].<span class="pl-smi">Ipv4</span>) != <span class="pl-c1">0</span> {</td>
-      </tr>
-      <tr>
-        <td id="L269" class="blob-num js-line-number" data-line-number="269"></td>
-        <td id="LC269" class="blob-code blob-code-inner js-file-line">				<span class="pl-c"><span class="pl-c">//</span>list the sending computer information</span></td>
-      </tr>
-      <tr>
-        <td id="L270" class="blob-num js-line-number" data-line-number="270"></td>
-        <td id="LC270" class="blob-code blob-code-inner js-file-line">				<span class="pl-c1">Listinterface</span>(intf[i])</td>
-      </tr>
-      <tr>
-        <td id="L271" class="blob-num js-line-number" data-line-number="271"></td>
-        <td id="LC271" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L272" class="blob-num js-line-number" data-line-number="272"></td>
-        <td id="LC272" class="blob-code blob-code-inner js-file-line">				<span class="pl-k">var</span> <span class="pl-smi">adr</span> <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L273" class="blob-num js-line-number" data-line-number="273"></td>
-        <td id="LC273" class="blob-code blob-code-inner js-file-line">				<span class="pl-k">var</span> <span class="pl-smi">bcadr</span> <span class="pl-k">string</span></td>
-      </tr>
-      <tr>
-        <td id="L274" class="blob-num js-line-number" data-line-number="274"></td>
-        <td id="LC274" class="blob-code blob-code-inner js-file-line">				adr = intf[i].<span class="pl-smi">Ipv4</span> + <span class="pl-s"><span class="pl-pds">&quot;</span>:0<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L275" class="blob-num js-line-number" data-line-number="275"></td>
-        <td id="LC275" class="blob-code blob-code-inner js-file-line">				bcadr = intf[i].<span class="pl-smi">Ipv4Bcast</span> + <span class="pl-s"><span class="pl-pds">&quot;</span>:1024<span class="pl-pds">&quot;</span></span></td>
-      </tr>
-      <tr>
-        <td id="L276" class="blob-num js-line-number" data-line-number="276"></td>
-        <td id="LC276" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L277" class="blob-num js-line-number" data-line-number="277"></td>
-        <td id="LC277" class="blob-code blob-code-inner js-file-line">				<span class="pl-c"><span class="pl-c">//</span> perform a discovery</span></td>
-      </tr>
-      <tr>
-        <td id="L278" class="blob-num js-line-number" data-line-number="278"></td>
-        <td id="LC278" class="blob-code blob-code-inner js-file-line">				<span class="pl-smi">str</span>, <span class="pl-smi">err</span> <span class="pl-k">:=</span> newopenhpsdr.<span class="pl-c1">Discover</span>(adr, bcadr, fg.<span class="pl-smi">Debug</span>)</td>
-      </tr>
-      <tr>
-        <td id="L279" class="blob-num js-line-number" data-line-number="279"></td>
-        <td id="LC279" class="blob-code blob-code-inner js-file-line">				<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L280" class="blob-num js-line-number" data-line-number="280"></td>
-        <td id="LC280" class="blob-code blob-code-inner js-file-line">					log.<span class="pl-c1">Println</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>Error <span class="pl-pds">&quot;</span></span>, err)</td>
-      </tr>
-      <tr>
-        <td id="L281" class="blob-num js-line-number" data-line-number="281"></td>
-        <td id="LC281" class="blob-code blob-code-inner js-file-line">				}</td>
-      </tr>
-      <tr>
-        <td id="L282" class="blob-num js-line-number" data-line-number="282"></td>
-        <td id="LC282" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L283" class="blob-num js-line-number" data-line-number="283"></td>
-        <td id="LC283" class="blob-code blob-code-inner js-file-line">				<span class="pl-c"><span class="pl-c">//</span>loop throught the list of discovered HPSDR boards</span></td>
-      </tr>
-      <tr>
-        <td id="L284" class="blob-num js-line-number" data-line-number="284"></td>
-        <td id="LC284" class="blob-code blob-code-inner js-file-line">				<span class="pl-k">for</span> <span class="pl-smi">i</span> <span class="pl-k">:=</span> <span class="pl-c1">0</span>; i &lt; <span class="pl-c1">len</span>(str); i++ {</td>
-      </tr>
-      <tr>
-        <td id="L285" class="blob-num js-line-number" data-line-number="285"></td>
-        <td id="LC285" class="blob-code blob-code-inner js-file-line">					<span class="pl-c1">Listboard</span>(str[i])</td>
-      </tr>
-      <tr>
-        <td id="L286" class="blob-num js-line-number" data-line-number="286"></td>
-        <td id="LC286" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L287" class="blob-num js-line-number" data-line-number="287"></td>
-        <td id="LC287" class="blob-code blob-code-inner js-file-line">					<span class="pl-k">if</span> fg.<span class="pl-smi">SelectMAC</span> == str[i].<span class="pl-smi">Macaddress</span> {</td>
-      </tr>
-      <tr>
-        <td id="L288" class="blob-num js-line-number" data-line-number="288"></td>
-        <td id="LC288" class="blob-code blob-code-inner js-file-line">						log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>      Selected MAC: (<span class="pl-c1">%s</span>) <span class="pl-c1">%s</span><span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, fg.<span class="pl-smi">SelectMAC</span>, str[i].<span class="pl-smi">Board</span>)</td>
-      </tr>
-      <tr>
-        <td id="L289" class="blob-num js-line-number" data-line-number="289"></td>
-        <td id="LC289" class="blob-code blob-code-inner js-file-line">						crtbd = str[i]</td>
-      </tr>
-      <tr>
-        <td id="L290" class="blob-num js-line-number" data-line-number="290"></td>
-        <td id="LC290" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L291" class="blob-num js-line-number" data-line-number="291"></td>
-        <td id="LC291" class="blob-code blob-code-inner js-file-line">						<span class="pl-k">if</span> (fgt.<span class="pl-smi">SetIP</span> != str[i].<span class="pl-smi">Baddress</span>) &amp;&amp; (fgt.<span class="pl-smi">SetIP</span> != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>) {</td>
-      </tr>
-      <tr>
-        <td id="L292" class="blob-num js-line-number" data-line-number="292"></td>
-        <td id="LC292" class="blob-code blob-code-inner js-file-line">							<span class="pl-c"><span class="pl-c">//</span>If the IPV4 changes</span></td>
-      </tr>
-      <tr>
-        <td id="L293" class="blob-num js-line-number" data-line-number="293"></td>
-        <td id="LC293" class="blob-code blob-code-inner js-file-line">							<span class="pl-k">if</span> strings.<span class="pl-c1">Contains</span>(*stip, <span class="pl-s"><span class="pl-pds">&quot;</span>255.255.255.255<span class="pl-pds">&quot;</span></span>) {</td>
-      </tr>
-      <tr>
-        <td id="L294" class="blob-num js-line-number" data-line-number="294"></td>
-        <td id="LC294" class="blob-code blob-code-inner js-file-line">								log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>     Changing IP address from <span class="pl-c1">%s</span> to DHCP address<span class="pl-cce">\n\n</span><span class="pl-pds">&quot;</span></span>, str[i].<span class="pl-smi">Baddress</span>)</td>
-      </tr>
-      <tr>
-        <td id="L295" class="blob-num js-line-number" data-line-number="295"></td>
-        <td id="LC295" class="blob-code blob-code-inner js-file-line">							} <span class="pl-k">else</span> {</td>
-      </tr>
-      <tr>
-        <td id="L296" class="blob-num js-line-number" data-line-number="296"></td>
-        <td id="LC296" class="blob-code blob-code-inner js-file-line">								log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>     Changing IP address from <span class="pl-c1">%s</span> to <span class="pl-c1">%s</span><span class="pl-cce">\n\n</span><span class="pl-pds">&quot;</span></span>, str[i].<span class="pl-smi">Baddress</span>, *stip)</td>
-      </tr>
-      <tr>
-        <td id="L297" class="blob-num js-line-number" data-line-number="297"></td>
-        <td id="LC297" class="blob-code blob-code-inner js-file-line">							}</td>
-      </tr>
-      <tr>
-        <td id="L298" class="blob-num js-line-number" data-line-number="298"></td>
-        <td id="LC298" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L299" class="blob-num js-line-number" data-line-number="299"></td>
-        <td id="LC299" class="blob-code blob-code-inner js-file-line">							<span class="pl-smi">_</span>, <span class="pl-smi">err</span> <span class="pl-k">:=</span> newopenhpsdr.<span class="pl-c1">Setip</span>(adr, bcadr, str[i], *stip, fg.<span class="pl-smi">Debug</span>)</td>
-      </tr>
-      <tr>
-        <td id="L300" class="blob-num js-line-number" data-line-number="300"></td>
-        <td id="LC300" class="blob-code blob-code-inner js-file-line">							<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L301" class="blob-num js-line-number" data-line-number="301"></td>
-        <td id="LC301" class="blob-code blob-code-inner js-file-line">								log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>Error <span class="pl-c1">%v</span><span class="pl-pds">&quot;</span></span>, err)</td>
-      </tr>
-      <tr>
-        <td id="L302" class="blob-num js-line-number" data-line-number="302"></td>
-        <td id="LC302" class="blob-code blob-code-inner js-file-line">								<span class="pl-c1">panic</span>(err)</td>
-      </tr>
-      <tr>
-        <td id="L303" class="blob-num js-line-number" data-line-number="303"></td>
-        <td id="LC303" class="blob-code blob-code-inner js-file-line">							}</td>
-      </tr>
-      <tr>
-        <td id="L304" class="blob-num js-line-number" data-line-number="304"></td>
-        <td id="LC304" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L305" class="blob-num js-line-number" data-line-number="305"></td>
-        <td id="LC305" class="blob-code blob-code-inner js-file-line">							<span class="pl-c"><span class="pl-c">//</span> perform a rediscovery</span></td>
-      </tr>
-      <tr>
-        <td id="L306" class="blob-num js-line-number" data-line-number="306"></td>
-        <td id="LC306" class="blob-code blob-code-inner js-file-line">							time.<span class="pl-c1">Sleep</span>(time.<span class="pl-c1">Duration</span>(fg.<span class="pl-smi">Ddelay</span>) * time.<span class="pl-smi">Second</span>)</td>
-      </tr>
-      <tr>
-        <td id="L307" class="blob-num js-line-number" data-line-number="307"></td>
-        <td id="LC307" class="blob-code blob-code-inner js-file-line">							str, err = newopenhpsdr.<span class="pl-c1">Discover</span>(adr, bcadr, fg.<span class="pl-smi">Debug</span>)</td>
-      </tr>
-      <tr>
-        <td id="L308" class="blob-num js-line-number" data-line-number="308"></td>
-        <td id="LC308" class="blob-code blob-code-inner js-file-line">							<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L309" class="blob-num js-line-number" data-line-number="309"></td>
-        <td id="LC309" class="blob-code blob-code-inner js-file-line">								log.<span class="pl-c1">Println</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>Error <span class="pl-pds">&quot;</span></span>, err)</td>
-      </tr>
-      <tr>
-        <td id="L310" class="blob-num js-line-number" data-line-number="310"></td>
-        <td id="LC310" class="blob-code blob-code-inner js-file-line">							}</td>
-      </tr>
-      <tr>
-        <td id="L311" class="blob-num js-line-number" data-line-number="311"></td>
-        <td id="LC311" class="blob-code blob-code-inner js-file-line">
-</td>
-      </tr>
-      <tr>
-        <td id="L312" class="blob-num js-line-number" data-line-number="312"></td>
-        <td id="LC312" class="blob-code blob-code-inner js-file-line">							<span class="pl-c1">Listboard</span>(str[i])</td>
-      </tr>
-      <tr>
-        <td id="L313" class="blob-num js-line-number" data-line-number="313"></td>
-        <td id="LC313" class="blob-code blob-code-inner js-file-line">						} <span class="pl-k">else</span> <span class="pl-k">if</span> *strbf != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span> {</td>
-      </tr>
-      <tr>
-        <td id="L314" class="blob-num js-line-number" data-line-number="314"></td>
-        <td id="LC314" class="blob-code blob-code-inner js-file-line">							<span class="pl-k">if</span> (fg.<span class="pl-smi">SelectMAC</span> != <span class="pl-s"><span class="pl-pds">&quot;</span>none<span class="pl-pds">&quot;</span></span>) &amp;&amp; (fg.<span class="pl-smi">SelectMAC</span> == str[i].<span class="pl-smi">Macaddress</span>) {</td>
-      </tr>
-      <tr>
-        <td id="L315" class="blob-num js-line-number" data-line-number="315"></td>
-        <td id="LC315" class="blob-code blob-code-inner js-file-line">								<span class="pl-k">if</span> strings.<span class="pl-c1">Contains</span>(strings.<span class="pl-c1">ToLower</span>(*strbf), strings.<span class="pl-c1">ToLower</span>(str[i].<span class="pl-smi">Board</span>)) {</td>
-      </tr>
-      <tr>
-        <td id="L316" class="blob-num js-line-number" data-line-number="316"></td>
-        <td id="LC316" class="blob-code blob-code-inner js-file-line">									<span class="pl-c"><span class="pl-c">//</span> erase the board flash memory</span></td>
-      </tr>
-      <tr>
-        <td id="L317" class="blob-num js-line-number" data-line-number="317"></td>
-        <td id="LC317" class="blob-code blob-code-inner js-file-line">									<span class="pl-c"><span class="pl-c">//</span>erstat, err := newopenhpsdr.Erase(str[i], fg.SetRBF, fg.Debug)</span></td>
-      </tr>
-      <tr>
-        <td id="L318" class="blob-num js-line-number" data-line-number="318"></td>
-        <td id="LC318" class="blob-code blob-code-inner js-file-line">									<span class="pl-c"><span class="pl-c">//</span>err := newopenhpsdr.Erase(crtbd, fg.Debug)</span></td>
-      </tr>
-      <tr>
-        <td id="L319" class="blob-num js-line-number" data-line-number="319"></td>
-        <td id="LC319" class="blob-code blob-code-inner js-file-line">									<span class="pl-smi">err</span> <span class="pl-k">:=</span> newopenhpsdr.<span class="pl-c1">Erase</span>(adr, str[i], fg.<span class="pl-smi">Debug</span>)</td>
-      </tr>
-      <tr>
-        <td id="L320" class="blob-num js-line-number" data-line-number="320"></td>
-        <td id="LC320" class="blob-code blob-code-inner js-file-line">									<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L321" class="blob-num js-line-number" data-line-number="321"></td>
-        <td id="LC321" class="blob-code blob-code-inner js-file-line">										<span class="pl-c1">panic</span>(err)</td>
-      </tr>
-      <tr>
-        <td id="L322" class="blob-num js-line-number" data-line-number="322"></td>
-        <td id="LC322" class="blob-code blob-code-inner js-file-line">									} <span class="pl-k">else</span> {</td>
-      </tr>
-      <tr>
-        <td id="L323" class="blob-num js-line-number" data-line-number="323"></td>
-        <td id="LC323" class="blob-code blob-code-inner js-file-line">										<span class="pl-c"><span class="pl-c">//</span>log.Printf(&quot; %v %v\n&quot;, erstat.Seconds, erstat.State)</span></td>
-      </tr>
-      <tr>
-        <td id="L324" class="blob-num js-line-number" data-line-number="324"></td>
-        <td id="LC324" class="blob-code blob-code-inner js-file-line">										<span class="pl-c"><span class="pl-c">//</span> send the RBF to the flash memory</span></td>
-      </tr>
-      <tr>
-        <td id="L325" class="blob-num js-line-number" data-line-number="325"></td>
-        <td id="LC325" class="blob-code blob-code-inner js-file-line">										<span class="pl-c"><span class="pl-c">//</span>time.Sleep(8 * time.Second)</span></td>
-      </tr>
-      <tr>
-        <td id="L326" class="blob-num js-line-number" data-line-number="326"></td>
-        <td id="LC326" class="blob-code blob-code-inner js-file-line">										<span class="pl-c"><span class="pl-c">//</span>newopenhpsdr.Program(str[i], fg.SetRBF, fg.Debug)</span></td>
-      </tr>
-      <tr>
-        <td id="L327" class="blob-num js-line-number" data-line-number="327"></td>
-        <td id="LC327" class="blob-code blob-code-inner js-file-line">										<span class="pl-smi">err</span> <span class="pl-k">:=</span> newopenhpsdr.<span class="pl-c1">Program</span>(adr, str[i], *strbf, fg.<span class="pl-smi">Debug</span>)</td>
-      </tr>
-      <tr>
-        <td id="L328" class="blob-num js-line-number" data-line-number="328"></td>
-        <td id="LC328" class="blob-code blob-code-inner js-file-line">										<span class="pl-k">if</span> err != <span class="pl-c1">nil</span> {</td>
-      </tr>
-      <tr>
-        <td id="L329" class="blob-num js-line-number" data-line-number="329"></td>
-        <td id="LC329" class="blob-code blob-code-inner js-file-line">											<span class="pl-c1">panic</span>(err)</td>
-      </tr>
-      <tr>
-        <td id="L330" class="blob-num js-line-number" data-line-number="330"></td>
-        <td id="LC330" class="blob-code blob-code-inner js-file-line">										}</td>
-      </tr>
-      <tr>
-        <td id="L331" class="blob-num js-line-number" data-line-number="331"></td>
-        <td id="LC331" class="blob-code blob-code-inner js-file-line">									}</td>
-      </tr>
-      <tr>
-        <td id="L332" class="blob-num js-line-number" data-line-number="332"></td>
-        <td id="LC332" class="blob-code blob-code-inner js-file-line">								} <span class="pl-k">else</span> {</td>
-      </tr>
-      <tr>
-        <td id="L333" class="blob-num js-line-number" data-line-number="333"></td>
-        <td id="LC333" class="blob-code blob-code-inner js-file-line">									log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span><span class="pl-cce">\n</span>      Input Check: RBF name <span class="pl-cce">\&quot;</span><span class="pl-c1">%s</span><span class="pl-cce">\&quot;</span> and selectedMAC board name <span class="pl-cce">\&quot;</span><span class="pl-c1">%s</span><span class="pl-cce">\&quot;</span> (<span class="pl-c1">%s</span>) do not match!<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>, *strbf, str[i].<span class="pl-smi">Board</span>, str[i].<span class="pl-smi">Macaddress</span>)</td>
-      </tr>
-      <tr>
-        <td id="L334" class="blob-num js-line-number" data-line-number="334"></td>
-        <td id="LC334" class="blob-code blob-code-inner js-file-line">									log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>       Please correct to program the board.<span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L335" class="blob-num js-line-number" data-line-number="335"></td>
-        <td id="LC335" class="blob-code blob-code-inner js-file-line">								}</td>
-      </tr>
-      <tr>
-        <td id="L336" class="blob-num js-line-number" data-line-number="336"></td>
-        <td id="LC336" class="blob-code blob-code-inner js-file-line">							} <span class="pl-k">else</span> {</td>
-      </tr>
-      <tr>
-        <td id="L337" class="blob-num js-line-number" data-line-number="337"></td>
-        <td id="LC337" class="blob-code blob-code-inner js-file-line">								log.<span class="pl-c1">Printf</span>(<span class="pl-s"><span class="pl-pds">&quot;</span>      Interface not active! <span class="pl-cce">\n</span><span class="pl-pds">&quot;</span></span>)</td>
-      </tr>
-      <tr>
-        <td id="L338" class="blob-num js-line-number" data-line-number="338"></td>
-        <td id="LC338" class="blob-code blob-code-inner js-file-line">							}</td>
-      </tr>
-      <tr>
-        <td id="L339" class="blob-num js-line-number" data-line-number="339"></td>
-        <td id="LC339" class="blob-code blob-code-inner js-file-line">						}</td>
-      </tr>
-      <tr>
-        <td id="L340" class="blob-num js-line-number" data-line-number="340"></td>
-        <td id="LC340" class="blob-code blob-code-inner js-file-line">					}</td>
-      </tr>
-      <tr>
-        <td id="L341" class="blob-num js-line-number" data-line-number="341"></td>
-        <td id="LC341" class="blob-code blob-code-inner js-file-line">				}</td>
-      </tr>
-      <tr>
-        <td id="L342" class="blob-num js-line-number" data-line-number="342"></td>
-        <td id="LC342" class="blob-code blob-code-inner js-file-line">			}</td>
-      </tr>
-      <tr>
-        <td id="L343" class="blob-num js-line-number" data-line-number="343"></td>
-        <td id="LC343" class="blob-code blob-code-inner js-file-line">		}</td>
-      </tr>
-      <tr>
-        <td id="L344" class="blob-num js-line-number" data-line-number="344"></td>
-        <td id="LC344" class="blob-code blob-code-inner js-file-line">	}</td>
-      </tr>
-      <tr>
-        <td id="L345" class="blob-num js-line-number" data-line-number="345"></td>
-        <td id="LC345" class="blob-code blob-code-inner js-file-line">}</td>
-      </tr>
-</table>
-
-  </div>
-
-</div>
-
-<button type="button" data-facebox="#jump-to-line" data-facebox-class="linejump" data-hotkey="l" class="d-none">Jump to Line</button>
-<div id="jump-to-line" style="display:none">
-  <!-- '"` --><!-- </textarea></xmp> --></option></form><form accept-charset="UTF-8" action="" class="js-jump-to-line-form" method="get"><div style="margin:0;padding:0;display:inline"><input name="utf8" type="hidden" value="&#x2713;" /></div>
-    <input class="form-control linejump-input js-jump-to-line-field" type="text" placeholder="Jump to line&hellip;" aria-label="Jump to line" autofocus>
-    <button type="submit" class="btn">Go</button>
-</form></div>
-
-
-  </div>
-  <div class="modal-backdrop js-touch-events"></div>
-</div>
-
-    </div>
-  </div>
-
-  </div>
-
-      <div class="container site-footer-container">
-  <div class="site-footer" role="contentinfo">
-    <ul class="site-footer-links float-right">
-        <li><a href="https://github.com/contact" data-ga-click="Footer, go to contact, text:contact">Contact GitHub</a></li>
-      <li><a href="https://developer.github.com" data-ga-click="Footer, go to api, text:api">API</a></li>
-      <li><a href="https://training.github.com" data-ga-click="Footer, go to training, text:training">Training</a></li>
-      <li><a href="https://shop.github.com" data-ga-click="Footer, go to shop, text:shop">Shop</a></li>
-        <li><a href="https://github.com/blog" data-ga-click="Footer, go to blog, text:blog">Blog</a></li>
-        <li><a href="https://github.com/about" data-ga-click="Footer, go to about, text:about">About</a></li>
-
-    </ul>
-
-    <a href="https://github.com" aria-label="Homepage" class="site-footer-mark" title="GitHub">
-      <svg aria-hidden="true" class="octicon octicon-mark-github" height="24" version="1.1" viewBox="0 0 16 16" width="24"><path fill-rule="evenodd" d="M8 0C3.58 0 0 3.58 0 8c0 3.54 2.29 6.53 5.47 7.59.4.07.55-.17.55-.38 0-.19-.01-.82-.01-1.49-2.01.37-2.53-.49-2.69-.94-.09-.23-.48-.94-.82-1.13-.28-.15-.68-.52-.01-.53.63-.01 1.08.58 1.23.82.72 1.21 1.87.87 2.33.66.07-.52.28-.87.51-1.07-1.78-.2-3.64-.89-3.64-3.95 0-.87.31-1.59.82-2.15-.08-.2-.36-1.02.08-2.12 0 0 .67-.21 2.2.82.64-.18 1.32-.27 2-.27.68 0 1.36.09 2 .27 1.53-1.04 2.2-.82 2.2-.82.44 1.1.16 1.92.08 2.12.51.56.82 1.27.82 2.15 0 3.07-1.87 3.75-3.65 3.95.29.25.54.73.54 1.48 0 1.07-.01 1.93-.01 2.2 0 .21.15.46.55.38A8.013 8.013 0 0 0 16 8c0-4.42-3.58-8-8-8z"/></svg>
-</a>
-    <ul class="site-footer-links">
-      <li>&copy; 2017 <span title="0.11816s from github-fe-13ea3e6.cp1-iad.github.net">GitHub</span>, Inc.</li>
-        <li><a href="https://github.com/site/terms" data-ga-click="Footer, go to terms, text:terms">Terms</a></li>
-        <li><a href="https://github.com/site/privacy" data-ga-click="Footer, go to privacy, text:privacy">Privacy</a></li>
-        <li><a href="https://github.com/security" data-ga-click="Footer, go to security, text:security">Security</a></li>
-        <li><a href="https://status.github.com/" data-ga-click="Footer, go to status, text:status">Status</a></li>
-        <li><a href="https://help.github.com" data-ga-click="Footer, go to help, text:help">Help</a></li>
-    </ul>
-  </div>
-</div>
-
-
-
-  
-
-  <div id="ajax-error-message" class="ajax-error-message flash flash-error">
-    <svg aria-hidden="true" class="octicon octicon-alert" height="16" version="1.1" viewBox="0 0 16 16" width="16"><path fill-rule="evenodd" d="M8.865 1.52c-.18-.31-.51-.5-.87-.5s-.69.19-.87.5L.275 13.5c-.18.31-.18.69 0 1 .19.31.52.5.87.5h13.7c.36 0 .69-.19.86-.5.17-.31.18-.69.01-1L8.865 1.52zM8.995 13h-2v-2h2v2zm0-3h-2V6h2v4z"/></svg>
-    <button type="button" class="flash-close js-flash-close js-ajax-error-dismiss" aria-label="Dismiss error">
-      <svg aria-hidden="true" class="octicon octicon-x" height="16" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M7.48 8l3.75 3.75-1.48 1.48L6 9.48l-3.75 3.75-1.48-1.48L4.52 8 .77 4.25l1.48-1.48L6 6.52l3.75-3.75 1.48 1.48z"/></svg>
-    </button>
-    You can't perform that action at this time.
-  </div>
-
-
-    
-    <script crossorigin="anonymous" integrity="sha256-tlavkG3w7CUvdoOudtuO01i/WzCb1XgfxGeuPB1XHCo=" src="https://assets-cdn.github.com/assets/frameworks-b656af906df0ec252f7683ae76db8ed358bf5b309bd5781fc467ae3c1d571c2a.js"></script>
-    <script async="async" crossorigin="anonymous" integrity="sha256-veisKGbF8lBr9zleY1cht4+0ITaJl97YbJQWgiipD2M=" src="https://assets-cdn.github.com/assets/github-bde8ac2866c5f2506bf7395e635721b78fb421368997ded86c94168228a90f63.js"></script>
-    
-    
-    
-    
-  <div class="js-stale-session-flash stale-session-flash flash flash-warn flash-banner d-none">
-    <svg aria-hidden="true" class="octicon octicon-alert" height="16" version="1.1" viewBox="0 0 16 16" width="16"><path fill-rule="evenodd" d="M8.865 1.52c-.18-.31-.51-.5-.87-.5s-.69.19-.87.5L.275 13.5c-.18.31-.18.69 0 1 .19.31.52.5.87.5h13.7c.36 0 .69-.19.86-.5.17-.31.18-.69.01-1L8.865 1.52zM8.995 13h-2v-2h2v2zm0-3h-2V6h2v4z"/></svg>
-    <span class="signed-in-tab-flash">You signed in with another tab or window. <a href="">Reload</a> to refresh your session.</span>
-    <span class="signed-out-tab-flash">You signed out in another tab or window. <a href="">Reload</a> to refresh your session.</span>
-  </div>
-  <div class="facebox" id="facebox" style="display:none;">
-  <div class="facebox-popup">
-    <div class="facebox-content" role="dialog" aria-labelledby="facebox-header" aria-describedby="facebox-description">
-    </div>
-    <button type="button" class="facebox-close js-facebox-close" aria-label="Close modal">
-      <svg aria-hidden="true" class="octicon octicon-x" height="16" version="1.1" viewBox="0 0 12 16" width="12"><path fill-rule="evenodd" d="M7.48 8l3.75 3.75-1.48 1.48L6 9.48l-3.75 3.75-1.48-1.48L4.52 8 .77 4.25l1.48-1.48L6 6.52l3.75-3.75 1.48 1.48z"/></svg>
-    </button>
-  </div>
-</div>
-
-
-  </body>
-</html>
-
+// Program to program HPSDR boards from the command line
+// new protocol version
+
+// by David R. Larsen KV0S, Copyright 2014-11-24
+//
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+const version string = "0.2.8"
+const protocol string = ">1.7"
+const update string = "2016-9-17"
+
+//  global current board
+var crtbd newopenhpsdr.Hpsdrboard
+
+// function to point users to the command list
+func usage() {
+	log.Printf("    For a list of flags use -help \n")
+	log.Printf("    For a list of commands use help \n\n")
+}
+
+// Function to print the program name info
+func program() {
+	log.Printf("HPSDRProgrammer_cmd  version:(%s)\n", version)
+	log.Printf("    By Dave KV0S, 2014-11-24, GPL2 \n\n")
+	log.Printf("        Protocol: %s \n", protocol)
+	log.Printf("    Last Updated: %s \n\n", update)
+}
+
+// Convenience function to print board data
+func Listboard(str newopenhpsdr.Hpsdrboard) {
+	if str.Macaddress != "0:0:0:0:0:0" {
+		log.Printf("\n")
+		log.Printf("        Board Type: %s\n", str.Board)
+		log.Printf("       HPSDR Board: (%s)\n", str.Macaddress)
+		log.Printf("     Board Address: %s\n", str.Baddress)
+		log.Printf("          Protocol: %s\n", str.Protocol)
+		log.Printf("          Firmware: %s\n", str.Firmware)
+		log.Printf("         Receivers: %d\n", str.Receivers)
+		log.Printf("       Freq. Input: %s\n", str.Freqinput)
+		log.Printf("    IQ data format: %s\n", str.Iqdata)
+		log.Printf("            Status: %s\n", str.Status)
+	}
+}
+
+// Convenience function to print an RBF manifest
+func Listmanifest(m newopenhpsdr.Manifest) {
+	log.Printf("\n")
+	log.Printf("          Manifest: %s\n", m.Path)
+	log.Printf("             Board: %s\n", m.Board)
+	log.Printf("          Firmware: %s\n", m.Firmware)
+	log.Printf("           SHA-256: %s\n", m.Sha256)
+	for _, n := range strings.Split(m.Notes, "\n") {
+		log.Printf("             Notes: %s\n", n)
+	}
+}
+
+// Convenience function to print the results of program -all
+func Listbatch(sum newopenhpsdr.Batchsummary) {
+	log.Printf("\n")
+	log.Printf("             Batch: %d boards, %d done, %d failed, %d skipped, %d canceled, %.1f seconds\n", sum.Boards, sum.Done, sum.Failed, sum.Skipped, sum.Canceled, sum.Seconds)
+	for _, res := range sum.Results {
+		if res.Error != "" {
+			log.Printf("    %17s: %-11s %-10s %3d retries %5.1f s  %s\n", res.Macaddress, res.Board, res.State, res.Retries, res.Seconds, res.Error)
+		} else {
+			log.Printf("    %17s: %-11s %-10s %3d retries %5.1f s\n", res.Macaddress, res.Board, res.State, res.Retries, res.Seconds)
+		}
+	}
+}
+
+// Convenience function to print a verification result
+func Listverify(res newopenhpsdr.Verifyresult) {
+	log.Printf("\n")
+	log.Printf("      Verify Board: (%s)\n", res.Macaddress)
+	log.Printf("             Found: %v\n", res.Found)
+	for _, c := range res.Checks {
+		log.Printf("%18s: want %s, got %s, passed %v\n", c.Field, c.Want, c.Got, c.Passed)
+	}
+	log.Printf("            Passed: %v\n", res.Passed)
+	log.Printf("           Seconds: %.1f\n", res.Seconds)
+	log.Printf("           Message: %s\n", res.Message)
+}
+
+// Convenience function to print interface data
+func Listinterface(itr newopenhpsdr.Intface) {
+	log.Printf("          Computer: (%v)\n", itr.MAC)
+	log.Printf("                OS: %s (%s) %d CPU(s)\n", runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	if runtime.GOARCH != "arm" {
+		u, err := user.Current()
+		if err != nil {
+			log.Println("User error", err)
+		} else {
+			log.Printf("          Username: %s (%s) %s\n", u.Name, u.Username, u.HomeDir)
+		}
+	}
+	for _, a := range itr.Ipv4addrs {
+		log.Printf("              IPV4: %v\n", a.Ipv4)
+		log.Printf("              Mask: %s\n", a.Netmask)
+		log.Printf("           Network: %v\n", a.Network)
+		log.Printf("         Broadcast: %v\n", a.Ipv4Bcast)
+	}
+	log.Printf("              IPV6: %v\n", itr.Ipv6)
+}
+
+// Report a failed board operation and exit
+func Fail(op string, err error) {
+	Report(op, err)
+	os.Exit(Exitcode(err))
+}
+
+// Report a failed board operation
+func Report(op string, err error) {
+	var pe *newopenhpsdr.ProgramError
+	switch {
+	case errors.As(err, &pe):
+		log.Printf("\n    %s failed: last good block %d of %d after %d retries (%v)\n", op, pe.Lastgood, pe.Blocks, pe.Retries, pe.Err)
+	case errors.Is(err, newopenhpsdr.ErrTimeout):
+		log.Printf("\n    %s failed: the board stopped answering (%v)\n", op, err)
+	default:
+		log.Printf("\n    %s failed: %v\n", op, err)
+	}
+}
+
+// Set the IP address of, or erase and program, the selected board
+func Runboard(adr string, bcadr string, brd newopenhpsdr.Hpsdrboard, fg flagsettings, fgt flagtemp, stip string, strbf string, vf bool, efw string, force bool) {
+	log.Printf("      Selected MAC: (%s) %s\n", brd.Macaddress, brd.Board)
+	crtbd = brd
+
+	if (fgt.SetIP != brd.Baddress) && (fgt.SetIP != "none") {
+		//If the IPV4 changes
+		nip, err := newopenhpsdr.Parseboardip(stip)
+		if err != nil {
+			Fail("Set IP", err)
+		}
+		if nip.Equal(newopenhpsdr.Dhcpaddress) {
+			log.Printf("     Changing IP address from %s to DHCP address\n\n", brd.Baddress)
+		} else {
+			log.Printf("     Changing IP address from %s to %s\n\n", brd.Baddress, nip)
+		}
+
+		// returns once a rediscovery finds the board at its new address
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(fg.Ddelay)*time.Second)
+		defer cancel()
+		msg, err := newopenhpsdr.SetipContext(ctx, adr, bcadr, brd, nip, fg.Debug)
+		if err != nil {
+			Fail("Set IP", err)
+		}
+		if msg.Warning != "" {
+			log.Printf("    Warning: %s\n", msg.Warning)
+		}
+		Listboard(*msg.Board)
+	} else if strbf != "none" {
+		if newopenhpsdr.Rbfforboard(brd, strbf) {
+			// check the file before the flash is erased
+			_, err := newopenhpsdr.Validaterbf(strbf, brd)
+			if err != nil {
+				Fail("RBF check", err)
+			}
+			m, found, err := newopenhpsdr.Checkmanifest(strbf, brd)
+			if found {
+				Listmanifest(m)
+			}
+			if err != nil {
+				if !force {
+					log.Printf("       Use -force to program anyway.\n")
+					Fail("Manifest check", err)
+				}
+				log.Printf("\n    Manifest check failed, forced: %v\n", err)
+			}
+
+			// erase the board flash memory, Ctrl-C only warns until done
+			ctx, stop := Flashcontext("erase and program")
+			err = newopenhpsdr.EraseContext(ctx, adr, brd, fg.Debug)
+			if err != nil {
+				Fail("Erase", err)
+			}
+
+			// send the RBF to the flash memory
+			err = newopenhpsdr.ProgramProgress(ctx, adr, brd, strbf, force, fg.Debug, nil)
+			stop()
+			if err != nil {
+				Fail("Program", err)
+			}
+
+			if vf {
+				// check the board came back with the new firmware
+				exp := newopenhpsdr.Rbfexpect(strbf)
+				if found && (m.Firmware != "") {
+					exp.Firmware = m.Firmware
+				}
+				if efw != "none" {
+					exp.Firmware = efw
+				}
+				res, err := newopenhpsdr.Verify(adr, bcadr, brd.Macaddress, exp, newopenhpsdr.Rebootdelay, fg.Debug)
+				Listverify(res)
+				if err != nil {
+					Fail("Verify", err)
+				}
+			}
+		} else {
+			log.Printf("\n      Input Check: RBF name \"%s\" and selectedMAC board name \"%s\" (%s) do not match!\n", strbf, brd.Board, brd.Macaddress)
+			log.Printf("       Please correct to program the board.\n")
+		}
+	}
+}
+
+func Listflags(fg flagsettings) {
+	log.Printf("    Saved Settings: \n")
+	log.Printf("         Interface: %v\n", fg.Intface)
+	log.Printf("             Index: %v\n", fg.Index)
+	log.Printf("          Filename: %v\n", fg.Filename)
+	log.Printf("      Selected MAC: (%v)\n", fg.SelectMAC)
+	log.Printf("            SetRBF: %v\n", fg.SetRBF)
+	log.Printf("             Debug: %v\n", fg.Debug)
+	log.Printf("            Ddelay: %d\n", fg.Ddelay)
+	log.Printf("           Dwindow: %d\n", fg.Dwindow)
+	log.Printf("             Board: %v\n", fg.Board)
+}
+
+func Listflagstemp(fgt flagtemp) {
+	log.Printf("     Temp settings: \n")
+	log.Printf("          Settings: %v\n", fgt.Settings)
+	log.Printf("             SetIP: %v\n", fgt.SetIP)
+	log.Printf("              Save: %v\n", fgt.Save)
+	log.Printf("              Load: %v\n", fgt.Load)
+}
+
+// Defaults of the timing flags.  A flag left at its default keeps the
+// value of a loaded settings file.
+const (
+	Defaultddelay  int = 8 // seconds for a board to answer at a new address
+	Defaultdwindow int = 2 // seconds to listen for discovery replies
+)
+
+func Initflags(fg *flagsettings) {
+	fg.Intface = "none"
+	fg.Filename = "none"
+	fg.SelectMAC = "none"
+	fg.SetRBF = "none"
+	fg.Debug = "none"
+	fg.Ddelay = Defaultddelay
+	fg.Dwindow = Defaultdwindow
+	fg.Board = "none"
+}
+
+type flagsettings struct {
+	Filename  string
+	Intface   string
+	Index     int
+	SelectMAC string
+	SetRBF    string
+	Debug     string
+	Ddelay    int
+	Dwindow   int
+	Board     string
+}
+
+type flagtemp struct {
+	SetIP    string
+	Settings string
+	Save     string
+	Load     string
+}
+
+func Initflagstemp(fgt *flagtemp) {
+	fgt.SetIP = "none"
+	fgt.Settings = "none"
+	fgt.Save = "none"
+	fgt.Load = "none"
+}
+
+func Parseflagstruct(fg *flagsettings, fgt *flagtemp, id int, stmac string, stip string, strbf string, db string, ss string, sv string, ld string, dd int, dw int, bd string) {
+
+	Initflags(fg)
+	Initflagstemp(fgt)
+
+	if (ld == "default") || (ld == "Default") {
+		fg.Filename = "HPSDRProgrammer_cmd.json"
+	} else if ld != "none" {
+		fg.Filename = ld
+	}
+
+	if ld != "none" {
+
+		dta, _ := ioutil.ReadFile(fg.Filename)
+		err := json.Unmarshal(dta, &fg)
+		if err != nil {
+			log.Println("error:", err)
+		}
+	}
+
+	//if ifn != "none" {
+	//	fg.Intface = ifn
+	//}
+	if id != 0 {
+		fg.Index = id
+	}
+	if stmac != "none" {
+		fg.SelectMAC = stmac
+	}
+	if strbf != "none" {
+		fg.SetRBF = strbf
+	}
+	if db != "none" {
+		fg.Debug = db
+	}
+	if dd != Defaultddelay {
+		fg.Ddelay = dd
+	}
+	if dw != Defaultdwindow {
+		fg.Dwindow = dw
+	}
+	if bd != "none" {
+		fg.Board = bd
+	}
+	if stip != "none" {
+		fgt.SetIP = stip
+	}
+	if ss != "none" {
+		fgt.Settings = ss
+	}
+	if sv == "default" {
+		fgt.Save = sv
+		fg.Filename = "HPSDRProgrammer_cmd.json"
+	} else if sv != "none" {
+		fg.Filename = sv
+		fgt.Save = sv
+	} else {
+		fgt.Save = sv
+	}
+	if ld == "default" {
+		fgt.Load = ld
+		fg.Filename = "HPSDRProgrammer_cmd.json"
+	} else if ld != "none" {
+		fg.Filename = ld
+		fgt.Load = ld
+	} else {
+		fgt.Load = ld
+	}
+
+	if fgt.Save != "none" {
+
+		f, err := os.Create(fg.Filename)
+		if err != nil {
+			log.Fatalf("Could not save settings: %v\n", err)
+		}
+		defer f.Close()
+
+		b, err := json.MarshalIndent(fg, "", "\t")
+		if err != nil {
+			log.Println("error:", err)
+		}
+
+		fmt.Fprintf(f, "%s\n", b)
+	}
+
+	if ss != "none" {
+		Listflags(*fg)
+		Listflagstemp(*fgt)
+	}
+
+}
+
+func main() {
+	var fg flagsettings
+	var fgt flagtemp
+	//var erstat newopenhpsdr.Erasestatus
+
+	// a first argument that is not a flag names a subcommand, the flags
+	// alone keep working for older scripts
+	if (len(os.Args) > 1) && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(Runcommand(os.Args[1], os.Args[2:]))
+	}
+
+	// Create the command line flags
+	//ifn := flag.String("interface", "none", "Select one interface number")
+	id := flag.Int("index", 0, "Select one interface by number")
+	stmac := flag.String("selectMAC", "none", "Select Board by MAC address")
+	stip := flag.String("setIP", "none", "Set IP address, unused number from your subnet or 255.255.255.255 for DHCP")
+	strbf := flag.String("setRBF", "none", "Select the RBF file to write to the board")
+	dd := flag.Int("ddelay", Defaultddelay, "Seconds to wait for the board to answer at its new address")
+	dw := flag.Int("dwindow", Defaultdwindow, "Seconds to listen for discovery replies")
+	flag.Int("edelay", 60, "Deprecated and ignored, kept so old scripts still run")
+	bd := flag.String("board", "none", "Board address or comma separated list, skips the interface selection (10.1.2.3)")
+	vf := flag.Bool("verify", false, "Rediscover the board after programming and check its firmware")
+	efw := flag.String("expectFW", "none", "Firmware version expected after programming, default from the RBF name")
+	force := flag.Bool("force", false, "Program even when the RBF manifest does not match the board or file")
+	db := flag.String("debug", "none", "Turn debugging and output type, (none, dec, hex)")
+	ss := flag.String("settings", "none", "Show the settings values (show)")
+	sv := flag.String("save", "none", "Save these current flags for future use in default or a named file")
+	ld := flag.String("load", "none", "Load a saved command file from default or a named file")
+	//cadr := flag.Bool("checkaddress", true, "check if new address is in subdomain and not restricted space")
+	//cbad := flag.Bool("checkboard", true, "check if new RBF file name has the same name as the board type")
+
+	flag.Parse()
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "edelay" {
+			log.Printf("    Warning: -edelay is no longer used and will be removed\n")
+		}
+	})
+
+	if flag.NFlag() < 1 {
+		program()
+		usage()
+	}
+
+	Parseflagstruct(&fg, &fgt, *id, *stmac, *stip, *strbf, *db, *ss, *sv, *ld, *dd, *dw, *bd)
+
+	if fg.Board != "none" {
+		// a board address skips the interface selection, the packets go
+		// straight to the board, through a router if needed
+		adr := "0.0.0.0:0"
+		bcadr := fg.Board
+		tgts := newopenhpsdr.Targetaddrs(bcadr)
+
+		str, err := newopenhpsdr.Discoverwait(adr, bcadr, time.Duration(fg.Dwindow)*time.Second, fg.Debug)
+		if err != nil {
+			log.Println("Error ", err)
+		}
+
+		for i := 0; i < len(str); i++ {
+			Listboard(str[i])
+
+			// one address is one board, a list needs the selectMAC flag
+			if (fg.SelectMAC == str[i].Macaddress) || ((fg.SelectMAC == "none") && (len(tgts) == 1)) {
+				Runboard(adr, bcadr, str[i], fg, fgt, *stip, *strbf, *vf, *efw, *force)
+			}
+		}
+		return
+	}
+
+	intf := newopenhpsdr.Interfaces()
+	for i := range intf {
+		if flag.NFlag() < 1 {
+			// if no flags list the interfaces in short form
+			log.Printf("    %d - %s (%s)\n", intf[i].Index, intf[i].Intname, intf[i].MAC)
+		} else if (flag.NFlag() == 1) && (fg.Index == 0) {
+			if fg.Debug == "none" {
+				// if one flag and it is debug = none, list the interface in short form
+				log.Printf("    %d - %s (%s)\n", intf[i].Index, intf[i].Intname, intf[i].MAC)
+			} else {
+				// if one flag and it is debug = dec or hex, list the interface in long form
+				log.Printf("    %d - %s (%s %s  %s\n", intf[i].Index, intf[i].Intname, intf[i].MAC, intf[i].Ipv4, intf[i].Ipv6)
+			}
+		}
+
+		// if ifn flag matches the current interface
+		if fg.Index == intf[i].Index {
+			if len(intf[i].Ipv4) != 0 {
+				//list the sending computer information
+				Listinterface(intf[i])
+
+				// discover on every IPv4 address of the interface, each one
+				// broadcasts to its own subnet
+				for _, a := range intf[i].Ipv4addrs {
+					var adr string
+					var bcadr string
+					adr = a.Ipv4 + ":0"
+					bcadr = a.Ipv4Bcast + ":1024"
+
+					// perform a discovery
+					str, err := newopenhpsdr.Discoverwait(adr, bcadr, time.Duration(fg.Dwindow)*time.Second, fg.Debug)
+					if err != nil {
+						log.Println("Error ", err)
+					}
+
+					//loop throught the list of discovered HPSDR boards
+					for i := 0; i < len(str); i++ {
+						Listboard(str[i])
+
+						if fg.SelectMAC == str[i].Macaddress {
+							Runboard(adr, bcadr, str[i], fg, fgt, *stip, *strbf, *vf, *efw, *force)
+						}
+					}
+				}
+			}
+		}
+	}
+}
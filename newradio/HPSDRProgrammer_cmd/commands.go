@@ -0,0 +1,717 @@
+// Subcommands of the command line programmer
+// new protocol version
+//
+// GPL2
+//
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Process exit codes
+const (
+	Exitok          int = 0 // the step worked
+	Exitfailed      int = 1 // the board operation failed
+	Exitusage       int = 2 // bad command line
+	Exitnotfound    int = 3 // no board, or not the selected one
+	Exittimeout     int = 4 // the board stopped answering
+	Exitfile        int = 5 // the RBF file cannot be used
+	Exitverify      int = 6 // the board answered with unexpected values
+	Exitinterrupted int = 7 // aborted by a second Ctrl-C
+)
+
+// One subcommand, run returns the exit code
+type command struct {
+	name string
+	help string
+	run  func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"interfaces", "List the network interfaces of this computer", Cmdinterfaces},
+		{"discover", "List the boards answering discovery", Cmddiscover},
+		{"info", "Show one board, selected by MAC address", Cmdinfo},
+		{"setip", "Set the IP address of one board", Cmdsetip},
+		{"erase", "Erase the flash memory of one board", Cmderase},
+		{"program", "Erase and write an RBF file to one board", Cmdprogram},
+		{"verify", "Check the board answers with the expected firmware", Cmdverify},
+		{"config", "Show or save the default flags in a settings file", Cmdconfig},
+	}
+}
+
+// List the subcommands
+func Commandusage() {
+	log.Printf("    Usage: HPSDRProgrammer_cmd <command> [flags]\n\n")
+	for _, c := range commands {
+		log.Printf("    %12s  %s\n", c.name, c.help)
+	}
+	log.Printf("\n    For the flags of a command use <command> -help \n\n")
+}
+
+// Run the subcommand name, returning the exit code
+func Runcommand(name string, args []string) int {
+	for _, c := range commands {
+		if c.name == name {
+			code := c.run(args)
+			out.finish(code)
+			return code
+		}
+	}
+	if (name == "help") || (name == "commands") {
+		program()
+		Commandusage()
+		return Exitok
+	}
+	log.Printf("    Unknown command: %s\n\n", name)
+	Commandusage()
+	return Exitusage
+}
+
+// Exit code for an error returned by newopenhpsdr
+func Exitcode(err error) int {
+	switch {
+	case err == nil:
+		return Exitok
+	case errors.Is(err, newopenhpsdr.ErrVerifyFailed):
+		return Exitverify
+	case errors.Is(err, newopenhpsdr.ErrBoardNotFound):
+		return Exitnotfound
+	case errors.Is(err, newopenhpsdr.ErrTimeout):
+		return Exittimeout
+	case errors.Is(err, newopenhpsdr.ErrFileInvalid):
+		return Exitfile
+	case errors.Is(err, newopenhpsdr.ErrAddressInvalid):
+		return Exitusage
+	case errors.Is(err, context.Canceled):
+		return Exitinterrupted
+	}
+	return Exitfailed
+}
+
+// Flags shared by the commands that talk to a board
+type cmdflags struct {
+	fs      *flag.FlagSet
+	config  string
+	index   int
+	board   string
+	mac     string
+	dwindow int
+	debug   string
+	output  string
+}
+
+// A local address and the broadcast or board addresses to send to
+type target struct {
+	adr   string
+	bcadr string
+}
+
+func newcmdflags(name string, usage string) *cmdflags {
+	cf := &cmdflags{fs: flag.NewFlagSet(name, flag.ContinueOnError)}
+	cf.fs.StringVar(&cf.config, "config", "none", "Load the default flags from a settings file, default or a named file")
+	cf.fs.IntVar(&cf.index, "index", 0, "Select one interface by number")
+	cf.fs.StringVar(&cf.board, "board", "none", "Board address or comma separated list, skips the interface selection")
+	cf.fs.StringVar(&cf.mac, "mac", "none", "Select Board by MAC address")
+	cf.fs.IntVar(&cf.dwindow, "dwindow", Defaultdwindow, "Seconds to listen for discovery replies")
+	cf.fs.StringVar(&cf.debug, "debug", "none", "Turn debugging and output type, (none, dec, hex)")
+	cf.fs.StringVar(&cf.output, "output", Outputtext, "Output on stdout, (text, json, ndjson)")
+	cf.fs.Usage = func() {
+		log.Printf("    Usage: HPSDRProgrammer_cmd %s [flags]\n", name)
+		log.Printf("        %s\n\n", usage)
+		cf.fs.SetOutput(os.Stderr)
+		cf.fs.PrintDefaults()
+	}
+	return cf
+}
+
+// Parse the command line, then fill the flags that were not given from
+// the settings file.
+func (cf *cmdflags) parse(args []string) int {
+	if err := cf.fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return Exitok
+		}
+		return Exitusage
+	}
+	if err := out.begin(cf.fs.Name(), cf.output); err != nil {
+		log.Printf("    %v\n\n", err)
+		return Exitusage
+	}
+	if cf.fs.NArg() > 0 {
+		log.Printf("    Unexpected arguments: %v\n\n", cf.fs.Args())
+		cf.fs.Usage()
+		return Exitusage
+	}
+	if cf.config == "none" {
+		return -1
+	}
+
+	var fg flagsettings
+	Initflags(&fg)
+	if err := Loadsettings(&fg, cf.config); err != nil {
+		log.Printf("    Could not load settings: %v\n", err)
+		out.fail(err)
+		return Exitusage
+	}
+	set := make(map[string]bool)
+	cf.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["index"] {
+		cf.index = fg.Index
+	}
+	if !set["board"] {
+		cf.board = fg.Board
+	}
+	if !set["mac"] {
+		cf.mac = fg.SelectMAC
+	}
+	if !set["dwindow"] {
+		cf.dwindow = fg.Dwindow
+	}
+	if !set["debug"] {
+		cf.debug = fg.Debug
+	}
+	return -1
+}
+
+// Addresses to discover on, from -board or from the interface -index
+func (cf *cmdflags) targets() (tgts []target, code int) {
+	if cf.board != "none" {
+		return []target{{"0.0.0.0:0", cf.board}}, Exitok
+	}
+	if cf.index == 0 {
+		log.Printf("    Select an interface with -index or a board with -board, see the interfaces command\n\n")
+		return tgts, Exitusage
+	}
+	for _, intf := range newopenhpsdr.Interfaces() {
+		if intf.Index != cf.index {
+			continue
+		}
+		for _, a := range intf.Ipv4addrs {
+			tgts = append(tgts, target{a.Ipv4 + ":0", a.Ipv4Bcast + ":" + newopenhpsdr.Boardport})
+		}
+		if len(tgts) == 0 {
+			log.Printf("    Interface %d (%s) has no IPv4 address\n\n", intf.Index, intf.Intname)
+			return tgts, Exitusage
+		}
+		return tgts, Exitok
+	}
+	log.Printf("    No interface with index %d\n\n", cf.index)
+	return tgts, Exitusage
+}
+
+// Discover on every target, returning each board with the target it
+// answered on.
+func (cf *cmdflags) discover() (strs []newopenhpsdr.Hpsdrboard, tgts []target, code int) {
+	all, code := cf.targets()
+	if code != Exitok {
+		return strs, tgts, code
+	}
+	for _, t := range all {
+		str, err := newopenhpsdr.Discoverwait(t.adr, t.bcadr, time.Duration(cf.dwindow)*time.Second, cf.debug)
+		if err != nil && !errors.Is(err, newopenhpsdr.ErrTimeout) {
+			log.Println("Error ", err)
+			out.fail(err)
+			return strs, tgts, Exitcode(err)
+		}
+		for i := range str {
+			strs = append(strs, str[i])
+			tgts = append(tgts, t)
+		}
+	}
+	if len(strs) == 0 {
+		log.Printf("    No board answered\n\n")
+		out.fail(fmt.Errorf("%w: no board answered", newopenhpsdr.ErrBoardNotFound))
+		return strs, tgts, Exitnotfound
+	}
+	return strs, tgts, Exitok
+}
+
+// Discover and pick the board selected by -mac.  Without -mac the board
+// is picked only when it is the only one answering.
+func (cf *cmdflags) selectboard() (brd newopenhpsdr.Hpsdrboard, tgt target, code int) {
+	strs, tgts, code := cf.discover()
+	if code != Exitok {
+		return brd, tgt, code
+	}
+	if cf.mac == "none" {
+		if len(strs) == 1 {
+			return strs[0], tgts[0], Exitok
+		}
+		log.Printf("    %d boards answered, select one with -mac\n\n", len(strs))
+		for i := range strs {
+			Listboard(strs[i])
+			out.emit("board", strs[i])
+		}
+		out.fail(fmt.Errorf("%d boards answered, select one with -mac", len(strs)))
+		return brd, tgt, Exitusage
+	}
+	for i := range strs {
+		if strings.EqualFold(strs[i].Macaddress, cf.mac) {
+			return strs[i], tgts[i], Exitok
+		}
+	}
+	log.Printf("    Board (%s) did not answer\n\n", cf.mac)
+	out.fail(&newopenhpsdr.BoardError{Macaddress: cf.mac})
+	return brd, tgt, Exitnotfound
+}
+
+// Run an erase or program step, reporting when it starts and ends, and
+// passing its progress to the output.
+func Runstep(typ string, brd newopenhpsdr.Hpsdrboard, file string, step func(obs newopenhpsdr.Observer) error) error {
+	start := time.Now()
+	out.emit(typ, Step{Macaddress: brd.Macaddress, State: "started", File: file})
+	err := step(out.progress)
+	st := Step{Macaddress: brd.Macaddress, State: "done", File: file, Seconds: time.Since(start).Seconds()}
+	if err != nil {
+		st.State = "failed"
+		st.Error = err.Error()
+		out.fail(err)
+	}
+	out.emit(typ, st)
+	return err
+}
+
+// Load saved flags from default or a named file
+func Loadsettings(fg *flagsettings, name string) error {
+	if (name == "default") || (name == "Default") {
+		name = "HPSDRProgrammer_cmd.json"
+	}
+	dta, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	fg.Filename = name
+	return json.Unmarshal(dta, fg)
+}
+
+func Cmdinterfaces(args []string) int {
+	fs := flag.NewFlagSet("interfaces", flag.ContinueOnError)
+	long := fs.Bool("long", false, "Show the addresses of every interface")
+	output := fs.String("output", Outputtext, "Output on stdout, (text, json, ndjson)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return Exitok
+		}
+		return Exitusage
+	}
+	if err := out.begin("interfaces", *output); err != nil {
+		log.Printf("    %v\n\n", err)
+		return Exitusage
+	}
+
+	for _, intf := range newopenhpsdr.Interfaces() {
+		if *long {
+			log.Printf("    %d - %s\n", intf.Index, intf.Intname)
+			Listinterface(intf)
+		} else {
+			log.Printf("    %d - %s (%s)\n", intf.Index, intf.Intname, intf.MAC)
+		}
+		out.emit("interface", intf)
+	}
+	return Exitok
+}
+
+func Cmddiscover(args []string) int {
+	cf := newcmdflags("discover", "List the boards answering discovery on an interface or at -board")
+	if code := cf.parse(args); code >= 0 {
+		return code
+	}
+
+	strs, _, code := cf.discover()
+	for i := range strs {
+		Listboard(strs[i])
+		out.emit("board", strs[i])
+	}
+	return code
+}
+
+func Cmdinfo(args []string) int {
+	cf := newcmdflags("info", "Show the board selected by -mac, or the only board answering")
+	if code := cf.parse(args); code >= 0 {
+		return code
+	}
+
+	brd, _, code := cf.selectboard()
+	if code != Exitok {
+		return code
+	}
+	Listboard(brd)
+	out.emit("board", brd)
+	log.Printf("          Mercury1: %s\n", brd.Atlas.Mercury1)
+	log.Printf("          Mercury2: %s\n", brd.Atlas.Mercury2)
+	log.Printf("          Mercury3: %s\n", brd.Atlas.Mercury3)
+	log.Printf("          Mercury4: %s\n", brd.Atlas.Mercury4)
+	log.Printf("          Penelope: %s\n", brd.Atlas.Penelope)
+	log.Printf("             Metis: %s\n", brd.Atlas.Metis)
+	if b, ok := newopenhpsdr.Boardbyid(byte(brd.Boardid)); ok {
+		log.Printf("            Models: %s\n", strings.Join(b.Models, ", "))
+		log.Printf("        Flash size: %d bytes\n", b.Flashsize)
+		log.Printf("     Max receivers: %d\n", b.Receivers)
+		log.Printf("         Protocols: %v\n", b.Protocols)
+	}
+	return Exitok
+}
+
+func Cmdsetip(args []string) int {
+	cf := newcmdflags("setip", "Set the IP address of a board, 255.255.255.255 returns it to DHCP")
+	ip := cf.fs.String("ip", "none", "New IP address, unused number from your subnet, or 255.255.255.255 or dhcp for DHCP")
+	dd := cf.fs.Int("ddelay", Defaultddelay, "Seconds to wait for the board to answer at its new address")
+	if code := cf.parse(args); code >= 0 {
+		return code
+	}
+	if *ip == "none" {
+		log.Printf("    The -ip flag is required\n\n")
+		return Exitusage
+	}
+	nip, err := newopenhpsdr.Parseboardip(*ip)
+	if err != nil {
+		log.Printf("    -ip: %v\n\n", err)
+		return Exitusage
+	}
+
+	brd, t, code := cf.selectboard()
+	if code != Exitok {
+		return code
+	}
+	Listboard(brd)
+	if nip.Equal(newopenhpsdr.Dhcpaddress) {
+		log.Printf("     Changing IP address from %s to DHCP address\n\n", brd.Baddress)
+	} else {
+		log.Printf("     Changing IP address from %s to %s\n\n", brd.Baddress, nip)
+	}
+
+	// Setip returns once a rediscovery finds the board at its new address
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*dd)*time.Second)
+	defer cancel()
+	msg, err := newopenhpsdr.SetipContext(ctx, t.adr, t.bcadr, brd, nip, cf.debug)
+	if err != nil {
+		log.Printf("\n    Set IP failed: %v\n", err)
+		out.fail(err)
+		return Exitcode(err)
+	}
+	out.emit("setip", msg)
+	Listboard(*msg.Board)
+	out.emit("board", *msg.Board)
+	return Exitok
+}
+
+func Cmderase(args []string) int {
+	cf := newcmdflags("erase", "Erase the flash memory of a board, it will not start until programmed")
+	if code := cf.parse(args); code >= 0 {
+		return code
+	}
+
+	brd, t, code := cf.selectboard()
+	if code != Exitok {
+		return code
+	}
+	Listboard(brd)
+	out.emit("board", brd)
+	ctx, stop := Flashcontext("erase")
+	defer stop()
+	if err := Runstep("erase", brd, "", func(obs newopenhpsdr.Observer) error {
+		return newopenhpsdr.EraseProgress(ctx, t.adr, brd, cf.debug, obs)
+	}); err != nil {
+		log.Printf("\n    Erase failed: %v\n", err)
+		return Exitcode(err)
+	}
+	return Exitok
+}
+
+func Cmdprogram(args []string) int {
+	cf := newcmdflags("program", "Erase a board and write an RBF file to its flash memory")
+	rbf := cf.fs.String("rbf", "none", "The RBF file to write to the board")
+	er := cf.fs.Bool("erase", true, "Erase the flash memory before programming")
+	force := cf.fs.Bool("force", false, "Program even when the RBF name or manifest does not match the board or file")
+	vf := cf.fs.Bool("verify", false, "Rediscover the board after programming and check its firmware")
+	efw := cf.fs.String("expectFW", "none", "Firmware version expected after programming, default from the RBF name")
+	all := cf.fs.Bool("all", false, "Program every board answering discovery that the RBF file is for")
+	parallel := cf.fs.Int("parallel", newopenhpsdr.Batchparallel, "Boards programmed at once with -all")
+	if code := cf.parse(args); code >= 0 {
+		return code
+	}
+	if *rbf == "none" {
+		log.Printf("    The -rbf flag is required\n\n")
+		return Exitusage
+	}
+	if *all {
+		return Programboards(cf, *rbf, *er, *force, *vf, *efw, *parallel)
+	}
+
+	brd, t, code := cf.selectboard()
+	if code != Exitok {
+		return code
+	}
+	Listboard(brd)
+	out.emit("board", brd)
+	if !*force && !newopenhpsdr.Rbfforboard(brd, *rbf) {
+		log.Printf("\n      Input Check: RBF name \"%s\" and selected board name \"%s\" (%s) do not match!\n", *rbf, brd.Board, brd.Macaddress)
+		log.Printf("       Please correct to program the board.\n")
+		out.fail(&newopenhpsdr.FileError{Filename: *rbf, Err: fmt.Errorf("name does not match the board %s", brd.Board)})
+		return Exitfile
+	}
+
+	// check the file before the flash is erased
+	m, found, err := Checkfile(brd, *rbf, *force)
+	if err != nil {
+		out.fail(err)
+		return Exitcode(err)
+	}
+
+	ctx, stop := Flashcontext("erase and program")
+	if *er {
+		if err := Runstep("erase", brd, "", func(obs newopenhpsdr.Observer) error {
+			return newopenhpsdr.EraseProgress(ctx, t.adr, brd, cf.debug, obs)
+		}); err != nil {
+			stop()
+			log.Printf("\n    Erase failed: %v\n", err)
+			return Exitcode(err)
+		}
+	}
+	err = Runstep("program", brd, *rbf, func(obs newopenhpsdr.Observer) error {
+		return newopenhpsdr.ProgramProgress(ctx, t.adr, brd, *rbf, *force, cf.debug, obs)
+	})
+	stop()
+	if err != nil {
+		Report("Program", err)
+		return Exitcode(err)
+	}
+
+	if *vf {
+		exp := newopenhpsdr.Rbfexpect(*rbf)
+		if found && (m.Firmware != "") {
+			exp.Firmware = m.Firmware
+		}
+		if *efw != "none" {
+			exp.Firmware = *efw
+		}
+		res, err := newopenhpsdr.Verify(t.adr, t.bcadr, brd.Macaddress, exp, newopenhpsdr.Rebootdelay, cf.debug)
+		Listverify(res)
+		out.emit("verify", res)
+		out.fail(err)
+		return Exitcode(err)
+	}
+	return Exitok
+}
+
+// Check the RBF file and its manifest for one board, before the flash is
+// erased.  force programs a file whose manifest does not match.
+func Checkfile(brd newopenhpsdr.Hpsdrboard, rbf string, force bool) (m newopenhpsdr.Manifest, found bool, er error) {
+	if _, err := newopenhpsdr.Validaterbf(rbf, brd); err != nil {
+		log.Printf("\n    RBF check failed: %v\n", err)
+		return m, false, err
+	}
+	m, found, err := newopenhpsdr.Checkmanifest(rbf, brd)
+	if found {
+		Listmanifest(m)
+		out.emit("manifest", m)
+	}
+	if err != nil {
+		if !force {
+			log.Printf("\n    Manifest check failed: %v\n", err)
+			log.Printf("       Use -force to program anyway.\n")
+			return m, found, err
+		}
+		log.Printf("\n    Manifest check failed, forced: %v\n", err)
+	}
+	return m, found, nil
+}
+
+// program -all: erase and program every board answering discovery that
+// the RBF file is for, parallel at a time, each from its own socket.
+// Boards of another type are skipped, the exit code is that of the first
+// board that failed.
+func Programboards(cf *cmdflags, rbf string, er bool, force bool, vf bool, efw string, parallel int) int {
+	if cf.mac != "none" {
+		log.Printf("    -all programs every matching board, it cannot be used with -mac\n\n")
+		return Exitusage
+	}
+	strs, tgts, code := cf.discover()
+	if code != Exitok {
+		return code
+	}
+
+	// the file checks run one board at a time, before any flash is erased
+	var jobs []newopenhpsdr.Batchjob
+	var bcast []string
+	var skipped []newopenhpsdr.Batchresult
+	var m newopenhpsdr.Manifest
+	var found bool
+	for i := range strs {
+		brd := strs[i]
+		Listboard(brd)
+		out.emit("board", brd)
+		res := newopenhpsdr.Batchresult{Macaddress: brd.Macaddress, Board: brd.Board, Baddress: brd.Baddress, Rbf: rbf, State: "skipped"}
+		if !force && !newopenhpsdr.Rbfforboard(brd, rbf) {
+			log.Printf("      Input Check: RBF name \"%s\" is not for board \"%s\" (%s), skipped\n", rbf, brd.Board, brd.Macaddress)
+			skipped = append(skipped, res)
+			continue
+		}
+		var err error
+		m, found, err = Checkfile(brd, rbf, force)
+		if err != nil {
+			res.State = "failed"
+			res.Err = err
+			res.Error = err.Error()
+			skipped = append(skipped, res)
+			continue
+		}
+		jobs = append(jobs, newopenhpsdr.Batchjob{Addr: tgts[i].adr, Board: brd, Rbf: rbf, Erase: er, Force: force})
+		bcast = append(bcast, tgts[i].bcadr)
+	}
+
+	ctx, stop := Flashcontext("erase and program")
+	sum := newopenhpsdr.Programall(ctx, jobs, parallel, cf.debug, out.progress)
+	stop()
+
+	// the boards reboot together, so only the first verify waits for it
+	if vf {
+		exp := newopenhpsdr.Rbfexpect(rbf)
+		if found && (m.Firmware != "") {
+			exp.Firmware = m.Firmware
+		}
+		if efw != "none" {
+			exp.Firmware = efw
+		}
+		wait := newopenhpsdr.Rebootdelay
+		for i := range sum.Results {
+			res := &sum.Results[i]
+			if res.State != "done" {
+				continue
+			}
+			vr, err := newopenhpsdr.Verify(jobs[i].Addr, bcast[i], res.Macaddress, exp, wait, cf.debug)
+			wait = 0
+			Listverify(vr)
+			out.emit("verify", vr)
+			if err != nil {
+				res.State = "failed"
+				res.Err = err
+				res.Error = err.Error()
+				sum.Done--
+				sum.Failed++
+			}
+		}
+	}
+
+	for _, res := range skipped {
+		if res.State == "skipped" {
+			sum.Skipped++
+		} else {
+			sum.Failed++
+		}
+		sum.Boards++
+		sum.Results = append(sum.Results, res)
+	}
+	Listbatch(sum)
+	out.emit("batch", sum)
+
+	if len(jobs) == 0 && sum.Failed == 0 {
+		log.Printf("    No board answering is one the RBF file is for\n\n")
+		out.fail(fmt.Errorf("%w: no board for %s", newopenhpsdr.ErrBoardNotFound, rbf))
+		return Exitnotfound
+	}
+	for _, res := range sum.Results {
+		if res.Err != nil {
+			out.fail(res.Err)
+			return Exitcode(res.Err)
+		}
+	}
+	return Exitok
+}
+
+func Cmdverify(args []string) int {
+	cf := newcmdflags("verify", "Check the board answers with the firmware of an RBF file or -expectFW")
+	rbf := cf.fs.String("rbf", "none", "RBF file name giving the expected board and firmware")
+	efw := cf.fs.String("expectFW", "none", "Firmware version expected")
+	wait := cf.fs.Int("wait", 0, "Seconds to wait for the board to reboot")
+	if code := cf.parse(args); code >= 0 {
+		return code
+	}
+	if cf.mac == "none" {
+		log.Printf("    The -mac flag is required\n\n")
+		return Exitusage
+	}
+
+	var exp newopenhpsdr.Expect
+	if *rbf != "none" {
+		exp = newopenhpsdr.Rbfexpect(*rbf)
+	}
+	if *efw != "none" {
+		exp.Firmware = *efw
+	}
+
+	tgts, code := cf.targets()
+	if code != Exitok {
+		return code
+	}
+	for _, t := range tgts {
+		res, err := newopenhpsdr.Verify(t.adr, t.bcadr, cf.mac, exp, time.Duration(*wait)*time.Second, cf.debug)
+		if errors.Is(err, newopenhpsdr.ErrBoardNotFound) && len(tgts) > 1 {
+			continue
+		}
+		Listverify(res)
+		out.emit("verify", res)
+		out.fail(err)
+		return Exitcode(err)
+	}
+	log.Printf("    Board (%s) did not answer\n\n", cf.mac)
+	out.fail(&newopenhpsdr.BoardError{Macaddress: cf.mac})
+	return Exitnotfound
+}
+
+func Cmdconfig(args []string) int {
+	cf := newcmdflags("config", "Show the settings, or save the given flags with -save")
+	sv := cf.fs.String("save", "none", "Save the flags for future use in default or a named file")
+	rbf := cf.fs.String("rbf", "none", "Select the RBF file to write to the board")
+	if code := cf.parse(args); code >= 0 {
+		return code
+	}
+
+	var fg flagsettings
+	Initflags(&fg)
+	fg.Index = cf.index
+	fg.Board = cf.board
+	fg.SelectMAC = cf.mac
+	fg.Dwindow = cf.dwindow
+	fg.Debug = cf.debug
+	fg.SetRBF = *rbf
+	if cf.config != "none" {
+		fg.Filename = cf.config
+	}
+
+	if *sv != "none" {
+		fg.Filename = *sv
+		if (*sv == "default") || (*sv == "Default") {
+			fg.Filename = "HPSDRProgrammer_cmd.json"
+		}
+		b, err := json.MarshalIndent(fg, "", "\t")
+		if err != nil {
+			log.Println("error:", err)
+			out.fail(err)
+			return Exitfailed
+		}
+		if err := ioutil.WriteFile(fg.Filename, []byte(fmt.Sprintf("%s\n", b)), 0644); err != nil {
+			log.Printf("    Could not save settings: %v\n", err)
+			out.fail(err)
+			return Exitfailed
+		}
+	}
+	Listflags(fg)
+	out.emit("config", fg)
+	return Exitok
+}
@@ -0,0 +1,66 @@
+// Ctrl-C while the flash of a board is being written
+// GPL2
+//
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// A second Ctrl-C within this time aborts the erase or program.
+const interruptwindow time.Duration = 5 * time.Second
+
+// Data of the interrupt event
+type Interrupt struct {
+	Signal string `json:"signal"`
+	Action string `json:"action"` // warn, or abort on the second signal
+}
+
+// Context for an erase or program run.  Ctrl-C or SIGTERM only warns that
+// stopping leaves the board with a partial flash, a second one within
+// interruptwindow cancels the context so the run ends cleanly with
+// context.Canceled.  Call stop when the run is over to restore the default
+// handling.
+func Flashcontext(what string) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		var warned time.Time
+		for {
+			select {
+			case s := <-sig:
+				if !warned.IsZero() && (time.Since(warned) < interruptwindow) {
+					log.Printf("\n    %v again: aborting the %s, the board is left with a partial flash!\n", s, what)
+					out.emit("interrupt", Interrupt{Signal: s.String(), Action: "abort"})
+					cancel()
+					return
+				}
+				warned = time.Now()
+				log.Printf("\n    %v: the %s is still running, stopping now leaves the board without working firmware.\n", s, what)
+				log.Printf("    Press Ctrl-C again within %v to abort anyway.\n", interruptwindow)
+				out.emit("interrupt", Interrupt{Signal: s.String(), Action: "warn"})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(sig)
+			close(done)
+			cancel()
+		})
+	}
+	return ctx, stop
+}
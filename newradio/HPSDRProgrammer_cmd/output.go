@@ -0,0 +1,187 @@
+// Machine readable output of the command line programmer
+// new protocol version
+//
+// GPL2
+//
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Version of the JSON output, raised when a field changes meaning or is
+// removed.  New fields may be added without raising it.
+const Outputschema int = 1
+
+// Output modes for the -output flag
+const (
+	Outputtext   string = "text"   // aligned labels on stderr only
+	Outputjson   string = "json"   // one document on stdout at the end
+	Outputndjson string = "ndjson" // one event per line on stdout as it happens
+)
+
+// One event, Type names what Data holds:
+//
+//	interface  newopenhpsdr.Intface
+//	board      newopenhpsdr.Hpsdrboard
+//	setip      newopenhpsdr.SetIPmessage
+//	erase      Step
+//	program    Step
+//	progress   newopenhpsdr.Progress, every block in ndjson mode only
+//	manifest   newopenhpsdr.Manifest
+//	verify     newopenhpsdr.Verifyresult
+//	batch      newopenhpsdr.Batchsummary, program -all
+//	interrupt  Interrupt, Ctrl-C during an erase or program
+//	config     flagsettings
+//	result     Result, always the last event
+type Event struct {
+	Schema  int         `json:"schema"`
+	Type    string      `json:"type"`
+	Command string      `json:"command"`
+	Time    string      `json:"time"`
+	Data    interface{} `json:"data"`
+}
+
+// Progress of an erase or program step
+type Step struct {
+	Macaddress string  `json:"macaddress"`
+	State      string  `json:"state"` // started, done, failed
+	File       string  `json:"file,omitempty"`
+	Seconds    float64 `json:"seconds"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Final outcome of a command, Exitcode is also the process exit code
+type Result struct {
+	Ok       bool    `json:"ok"`
+	Exitcode int     `json:"exitcode"`
+	Status   string  `json:"status"` // ok, failed, usage, notfound, timeout, file, verify
+	Error    string  `json:"error,omitempty"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// Document written in json mode
+type Document struct {
+	Schema  int     `json:"schema"`
+	Command string  `json:"command"`
+	Events  []Event `json:"events"`
+	Result  Result  `json:"result"`
+}
+
+// Collects the events of one command, program -all emits from several
+// goroutines at once
+type emitter struct {
+	mu      sync.Mutex
+	mode    string
+	command string
+	start   time.Time
+	events  []Event
+	err     error
+}
+
+// output of the running command
+var out = &emitter{mode: Outputtext}
+
+// Start a command, checking the output mode.
+func (o *emitter) begin(command string, mode string) error {
+	o.command = command
+	o.start = time.Now()
+	o.events = []Event{}
+	o.err = nil
+	switch mode {
+	case Outputtext, Outputjson, Outputndjson:
+		o.mode = mode
+		return nil
+	}
+	o.mode = Outputtext
+	return fmt.Errorf("unknown output %q, use text, json or ndjson", mode)
+}
+
+// Record an event, written at once in ndjson mode.
+func (o *emitter) emit(typ string, data interface{}) {
+	if o.mode == Outputtext {
+		return
+	}
+	ev := Event{Schema: Outputschema, Type: typ, Command: o.command, Time: time.Now().UTC().Format(time.RFC3339Nano), Data: data}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.mode == Outputndjson {
+		b, err := json.Marshal(ev)
+		if err == nil {
+			fmt.Fprintf(os.Stdout, "%s\n", b)
+		}
+		return
+	}
+	o.events = append(o.events, ev)
+}
+
+// Observer for Erase and Program.  Every event is written in ndjson mode,
+// json keeps the events that are not per block, and text logs the
+// throughput every tenth of the file.
+func (o *emitter) progress(p newopenhpsdr.Progress) {
+	perblock := (p.Kind == newopenhpsdr.Blocksent) || (p.Kind == newopenhpsdr.Blockacked)
+	switch o.mode {
+	case Outputndjson:
+		o.emit("progress", p)
+	case Outputjson:
+		if !perblock {
+			o.emit("progress", p)
+		}
+	default:
+		if (p.Kind == newopenhpsdr.Blockacked) && (p.Blocks >= 10) && ((p.Block+1)%(p.Blocks/10) == 0) {
+			log.Printf("          Progress: (%s) block %d of %d, %.0f bytes/s, %d retries, %.1f seconds left\n", p.Macaddress, p.Block+1, p.Blocks, p.Rate, p.Retries, p.Eta.Seconds())
+		}
+	}
+}
+
+// Remember why the command failed, for the result.
+func (o *emitter) fail(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.err = err
+}
+
+// Write the result of the command.
+func (o *emitter) finish(code int) {
+	res := Result{Ok: code == Exitok, Exitcode: code, Status: Exitstatus(code), Seconds: time.Since(o.start).Seconds()}
+	if o.err != nil {
+		res.Error = o.err.Error()
+	}
+	switch o.mode {
+	case Outputndjson:
+		o.emit("result", res)
+	case Outputjson:
+		b, err := json.MarshalIndent(Document{Schema: Outputschema, Command: o.command, Events: o.events, Result: res}, "", "\t")
+		if err == nil {
+			fmt.Fprintf(os.Stdout, "%s\n", b)
+		}
+	}
+}
+
+// Name of an exit code for the result
+func Exitstatus(code int) string {
+	switch code {
+	case Exitok:
+		return "ok"
+	case Exitusage:
+		return "usage"
+	case Exitnotfound:
+		return "notfound"
+	case Exittimeout:
+		return "timeout"
+	case Exitfile:
+		return "file"
+	case Exitverify:
+		return "verify"
+	case Exitinterrupted:
+		return "interrupted"
+	}
+	return "failed"
+}
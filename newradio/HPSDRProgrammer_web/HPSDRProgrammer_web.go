@@ -7,15 +7,19 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"html/template"
-	"io"
 	"io/ioutil"
 	"log"
 	"math"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/user"
 	"runtime"
@@ -162,25 +166,36 @@ performs the same function as the HPSDRProgrammer.  It perform the following tas
 
 // Counter window text
 const w1cnt string = `
-<script type="text/javascript" src="http://{{.Address}}:{{.Port}}/js/lib/jquery-1.12.1.min.js"></script>
+<script type="text/javascript" src="/js/lib/jquery-1.12.1.min.js"></script>
 <script type="text/javascript" >
-	var wsUri = "ws://{{.Address}}:{{.Port}}/counter/";
+	var wsUri = (location.protocol == "https:" ? "wss://" : "ws://") + location.host + "/counter/?job={{.Job}}";
 	var output;
 	var packet;
+	var verify;
+	var bar;
 	function init() {
 			  output = document.getElementById("output");
 			  packet = document.getElementById("packet");
+			  verify = document.getElementById("verify");
+			  bar = document.getElementById("bar");
 			  websocket = new WebSocket(wsUri);
 			  websocket.onmessage = function(evt) { onMessage(evt) };
 			  websocket.onerror = function(evt) { onError(evt) }; }
    function onMessage(evt) {
 			  console.log(evt.data);
-			  writeToScreen(evt.data); }
+			  writeToScreen(JSON.parse(evt.data)); }
     //function onError(evt) {
 	 //		  writeToScreen('<span style="color: red;">ERROR:<\/span> ' + evt.data); }
-    function writeToScreen(message) {
-			  output.innerHTML = message.split(",")[0];
-			  packet.innerHTML = message.split(",")[1]; }
+    function writeToScreen(f) {
+			  output.innerHTML = f.erase;
+			  packet.innerHTML = f.program;
+			  if (bar) {
+					bar.value = f.percent; }
+			  if (verify) {
+					verify.innerHTML = f.verify; }
+			  if (f.state == "failed") {
+					output.style.color = "red";
+					packet.style.color = "red"; } }
 			  window.addEventListener("load", init, false);
 </script>
 `
@@ -200,13 +215,12 @@ $(document).ready(function() {
 /script>
 `
 
-// used get the selected information to different parts of the code with out closure tricks on Handlers
-// current selected board
-var crtbd newopenhpsdr.Hpsdrboard
-
-// current file
+// directory the uploaded RBF files are kept in, set once at start up.  The
+// board and file being programmed belong to a Job, see jobs.go
 var rbffiledir string
-var rbffilename string
+
+// packet output of the board operations, (none, dec, hex) from -debug
+var debug string = "none"
 
 //
 func usage() {
@@ -223,10 +237,11 @@ func Computertable(itr newopenhpsdr.Intface) (str string) {
 	if runtime.GOARCH != "arm" {
 		u, err := user.Current()
 		if err != nil {
-			panic(err.Error())
+			log.Println("User error", err)
+		} else {
+			s = fmt.Sprintf("<td align=\"right\"><b>User:</b></td><td> %s (%s) %s </td></tr><tr>", u.Name, u.Username, u.HomeDir)
+			strs = append(strs, s)
 		}
-		s = fmt.Sprintf("<td align=\"right\"><b>User:</b></td><td> %s (%s) %s </td></tr><tr>", u.Name, u.Username, u.HomeDir)
-		strs = append(strs, s)
 	}
 	s = fmt.Sprintf("<td align=\"right\"><b>IPV4:</b></td><td> %v</td></tr><tr>", itr.Ipv4)
 	strs = append(strs, s)
@@ -250,6 +265,7 @@ type Html struct {
 	Update   string
 	Address  string
 	Port     string
+	Job      string
 }
 
 // Convenience function to print interface data
@@ -259,9 +275,10 @@ func Listinterface(itr newopenhpsdr.Intface) {
 	if runtime.GOARCH != "arm" {
 		u, err := user.Current()
 		if err != nil {
-			panic(err.Error())
+			log.Println("User error", err)
+		} else {
+			log.Printf("          Username: %s (%s) %s\n", u.Name, u.Username, u.HomeDir)
 		}
-		log.Printf("          Username: %s (%s) %s\n", u.Name, u.Username, u.HomeDir)
 	}
 	log.Printf("              Name: %v\n", itr.Intname)
 	log.Printf("               MAC: %v\n", itr.MAC)
@@ -325,7 +342,20 @@ func introhandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<form method=\"link\" action=\"/closescreen/\" >")
 	fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"quit\" value=\"quit\"> Quit</button>")
 	fmt.Fprintf(w, "</form>")
-	fmt.Fprintf(w, "</td></tr>")
+	fmt.Fprintf(w, "</td>")
+	if Authrequired() {
+		fmt.Fprintf(w, "<td>")
+		if requestsession(r).authed {
+			fmt.Fprintf(w, "<form method=\"link\" action=\"/logout/\" >")
+			fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\"> Logout</button>")
+		} else {
+			fmt.Fprintf(w, "<form method=\"link\" action=\"/login/\" >")
+			fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\"> Login</button>")
+		}
+		fmt.Fprintf(w, "</form>")
+		fmt.Fprintf(w, "</td>")
+	}
+	fmt.Fprintf(w, "</tr>")
 	fmt.Fprintf(w, "</table>")
 
 	fmt.Fprintf(w, "</body>\n")
@@ -346,19 +376,29 @@ func nichandler(w http.ResponseWriter, r *http.Request) {
 
 	r.ParseForm()
 
-	crtbd = newopenhpsdr.ResetHpsdrboard(crtbd)
+	// a new selection starts over, on the job of this browser
+	j := Jobfor(r)
+	j.Update(func(st *Jobstate) {
+		st.Board = newopenhpsdr.ResetHpsdrboard(st.Board)
+		st.State = "new"
+	})
 
-	str := fmt.Sprintf("http://%s:%s/nic/json/", srvaddress, srvport)
+	str := fmt.Sprintf("%s://%s:%s/nic/json/", srvscheme, srvaddress, srvport)
 
-	res, err := http.Get(str)
+	res, err := Selfget(str)
 	if err != nil {
-		log.Fatal(err)
+		log.Println("Interface json error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
 
 	if err != nil {
-		panic(err.Error())
+		log.Println("Read Error ", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
 
 	var intf []newopenhpsdr.Intface
@@ -387,6 +427,7 @@ func nichandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<br/>\n")
 	fmt.Fprintf(w, "<table><tr><td valign=\"top\">")
 	fmt.Fprintf(w, "<form action=\"/board/\" >")
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", j.State().ID)
 	if r.FormValue("index") == "0" {
 		fmt.Fprintf(w, "<b>Select Network interface</b>")
 	} else {
@@ -444,6 +485,8 @@ func boardhandler(w http.ResponseWriter, r *http.Request) {
 	t3.Execute(w, H)
 
 	r.ParseForm()
+	j := Jobfor(r)
+	id := j.State().ID
 
 	fmt.Fprintf(w, "<h2>Computer</h2> ")
 
@@ -472,13 +515,18 @@ func boardhandler(w http.ResponseWriter, r *http.Request) {
 	//bcadr = itr.Ipv4Bcast + ":1024"
 
 	// perform a discovery
-	sd := fmt.Sprintf("http://%s:%s/discover/json/?index=%d", srvaddress, srvport, itr.Index)
+	sd := fmt.Sprintf("%s://%s:%s/discover/json/?index=%d", srvscheme, srvaddress, srvport, itr.Index)
 	log.Printf("Discovery Call: %s\n", sd)
 
-	res, err := http.Get(sd)
+	res, err := Selfget(sd)
 	if err != nil {
-		log.Fatal(err)
+		log.Println("Discovery json error", err)
+		fmt.Fprintf(w, "<p><b>Discovery failed:</b> %s</p>\n", template.HTMLEscapeString(err.Error()))
+		fmt.Fprintf(w, "</body>\n")
+		fmt.Fprintf(w, "</html>\n")
+		return
 	}
+	defer res.Body.Close()
 
 	log.Printf("Reply Header: %s\n ", res.Header)
 	log.Printf("Reply Body: %s\n ", res.Body)
@@ -503,6 +551,7 @@ func boardhandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<br/><br/>\n")
 	fmt.Fprintf(w, "<table>\n<tr>\n<td align=\"right\">\n")
 	fmt.Fprintf(w, "<form action=\"/board/\" >\n")
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", id)
 	if r.FormValue("index") == "0" {
 		fmt.Fprintf(w, "<b>Select Network interface</b>")
 	} else {
@@ -531,7 +580,14 @@ func boardhandler(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprintf(w, "<option selected value=\"%s\">%s (%s)</option>\n", str[i].Macaddress, str[i].Board, str[i].Macaddress)
 			//istr = strings.Split(str[i].Baddress, ".")
 			boardtype = str[i].Board
-			crtbd = str[i]
+			bd := str[i]
+			j.Update(func(st *Jobstate) {
+				st.Board = bd
+				st.Index = itr.Index
+				st.Intface = itr.Intname
+				st.State = "selected"
+			})
+			log.Printf("Job %s selected %s (%s) on %s\n", id, bd.Board, bd.Macaddress, itr.Intname)
 		} else {
 			fmt.Fprintf(w, "<option value=\"%s\">%s (%s)</option>\n", str[i].Macaddress, str[i].Board, str[i].Macaddress)
 		}
@@ -550,20 +606,28 @@ func boardhandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</table>\n")
 
 	if r.FormValue("board") != "" {
+		bd := j.State().Board
 		fmt.Fprintf(w, "<b>Select HPSDR Board</b>")
 		fmt.Fprintf(w, "<table>\n")
-		fmt.Fprintf(w, "%s", newopenhpsdr.Hpsdrboardtable(crtbd))
-		Listboard(crtbd)
+		fmt.Fprintf(w, "%s", newopenhpsdr.Hpsdrboardtable(bd))
+		Listboard(bd)
 
 		fmt.Fprintf(w, "</td><td valign=\"top\">")
 		fmt.Fprintf(w, "<form method=\"link\" action=\"/setip/\" >")
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", id)
 		fmt.Fprintf(w, "<input type=\"hidden\" name=\"index\" value=%s>\n", r.FormValue("index"))
 		fmt.Fprintf(w, "<input type=\"hidden\" name=\"board\" value=%s>\n", r.FormValue("board"))
-		fmt.Fprintf(w, "<input type=\"hidden\" name=\"baddress\" value=%s>\n", crtbd.Baddress)
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"baddress\" value=%s>\n", bd.Baddress)
 		fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"setip\" value=\"setip\"> Change IP</button>")
 		fmt.Fprintf(w, "</form>")
 		fmt.Fprintf(w, "</td><td valign=\"top\">")
+		fmt.Fprintf(w, "<form method=\"link\" action=\"/batch/\" >")
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"index\" value=%s>\n", r.FormValue("index"))
+		fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"batch\" value=\"batch\"> Program several</button>")
+		fmt.Fprintf(w, "</form>")
+		fmt.Fprintf(w, "</td><td valign=\"top\">")
 		fmt.Fprintf(w, "<form method=\"link\" action=\"/prog/\" >")
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", id)
 		fmt.Fprintf(w, "<input type=\"hidden\" name=\"index\" value=%s>\n", r.FormValue("index"))
 		fmt.Fprintf(w, "<input type=\"hidden\" name=\"boardtype\" value=%s>\n", boardtype)
 		fmt.Fprintf(w, "<input type=\"hidden\" name=\"board\" value=%s>\n", r.FormValue("board"))
@@ -621,6 +685,7 @@ func setiphandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<table>\n")
 	fmt.Fprintf(w, "</td><td valign=\"top\">")
 	fmt.Fprintf(w, "<form method=\"link\" action=\"/changedip/\" >")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/changedip/"))
 	//fmt.Fprintf(w, "<form >")
 	s, _ := strconv.ParseInt(aa[0], 10, 32)
 	fmt.Fprintf(w, "<input class=\"intp\" type=\"number\" min=\"0\" max=\"254\" name=\"ip1\" value=%d>\n", s)
@@ -642,6 +707,7 @@ func setiphandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</form>")
 	fmt.Fprintf(w, "</td><td valign=\"top\">")
 	fmt.Fprintf(w, "<form method=\"link\" action=\"/changedip/\" >")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/changedip/"))
 	fmt.Fprintf(w, "<input type=\"hidden\" name=\"oldaddress\" value=%s>\n", adr)
 	fmt.Fprintf(w, "<input type=\"hidden\" name=\"index\" value=%s>\n", nic)
 	fmt.Fprintf(w, "<input type=\"hidden\" name=\"boardtype\" value=%s>\n", boardtype)
@@ -674,6 +740,7 @@ func prghandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 	boardtype := r.FormValue("boardtype")
 	intf := r.FormValue("index")
+	j := Jobfor(r)
 
 	t, _ := template.New("head").Parse(w1p)
 	t.Execute(w, "head")
@@ -689,30 +756,36 @@ func prghandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<h2>Program Interfaces</h2> <p> Please select the interface to perform a Board Program</p>")
 	fmt.Fprintf(w, "<legend>Get the latest RBF file from the repository</legend>\n")
 	if boardtype == "METIS" {
-		fmt.Fprintf(w, "<a href=\"http://%s:%s/nosite/\">%s</a><br/>\n", srvaddress, srvport, boardtype)
+		fmt.Fprintf(w, "<a href=\"/nosite/\">%s</a><br/>\n", boardtype)
 		//fmt.Fprintf(w, "<a href=\"http://svn.tapr.org/repos_sdr_hpsdr/trunk/Metis/Release/\">%s</a><br/>\n", boardtype)
 	} else if boardtype == "HERMES" {
-		fmt.Fprintf(w, "<a href=\"http://%s:%s/nosite/\">%s</a><br/>\n", srvaddress, srvport, boardtype)
+		fmt.Fprintf(w, "<a href=\"/nosite/\">%s</a><br/>\n", boardtype)
 		//fmt.Fprintf(w, "<a href=\"http://svn.tapr.org/repos_sdr_hpsdr/trunk/Hermes/Release/\">%s</a><br/>\n", boardtype)
 	} else if boardtype == "ANGELIA" {
-		fmt.Fprintf(w, "<a href=\"http://%s:%s/nosite/\">%s</a><br/>\n", srvaddress, srvport, boardtype)
+		fmt.Fprintf(w, "<a href=\"/nosite/\">%s</a><br/>\n", boardtype)
 		//fmt.Fprintf(w, "<a href=\"http://www.k5so.com/HPSDR_downloads.html\">%s</a><br/>\n", boardtype)
 	} else if boardtype == "ORIAN" {
-		fmt.Fprintf(w, "<a href=\"http://%s:%s/nosite/\">%s</a><br/>\n", srvaddress, srvport, boardtype)
+		fmt.Fprintf(w, "<a href=\"/nosite/\">%s</a><br/>\n", boardtype)
 		//fmt.Fprintf(w, "<a href=\"http://www.k5so.com/HPSDR_downloads.html\">%s</a><br/>\n", boardtype)
 	}
 
 	fmt.Fprintf(w, "<br/>")
 
 	fmt.Fprintf(w, "<form enctype=\"multipart/form-data\" action=\"/upload/\" method=\"post\">")
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", j.State().ID)
 	fmt.Fprintf(w, "<input type=\"hidden\" name=\"index\" value=%s>\n", intf)
 	fmt.Fprintf(w, "<input type=\"hidden\" name=\"boardtype\" value=%s>\n", boardtype)
-	fmt.Fprintf(w, "<input type=\"hidden\" name=\"token\" value=\"{{.}}\">\n")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/upload/"))
 	fmt.Fprintf(w, " Select a file: <input class=\"fileintp\" type=\"file\" accept=\".rbf, .RBF\" name=\"uploadfile\" id=\"uploadfile\">")
 	fmt.Fprintf(w, "  <input class=\"btn\" type=\"submit\" value=\"Upload\">")
 	fmt.Fprintf(w, "</form>")
 	fmt.Fprintf(w, "<br/>")
 
+	fmt.Fprintf(w, "<form method=\"link\" action=\"/library/\" >")
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", j.State().ID)
+	fmt.Fprintf(w, " Or program an image uploaded before: <button class=\"btn\" type=\"submit\"> Firmware Library</button>")
+	fmt.Fprintf(w, "</form>")
+
 	fmt.Fprintf(w, "</body>\n")
 	fmt.Fprintf(w, "</html>\n")
 }
@@ -729,6 +802,9 @@ func filehandler(w http.ResponseWriter, r *http.Request) {
 	H.Port = srvport
 
 	r.ParseForm()
+	if Csrfrefused(w, r, "/file/") {
+		return
+	}
 
 	filename := r.FormValue("img")
 	//boardtype := r.FormValue("boardtype")
@@ -746,18 +822,41 @@ func filehandler(w http.ResponseWriter, r *http.Request) {
 	t4, _ := template.New("webbanner").Parse(banner)
 	t4.Execute(w, H)
 
+	// the websocket of this page programs the board and file of the job
+	j, err := Findjob(r.FormValue("job"))
+	if err != nil {
+		log.Println("Program page ", err)
+		Errorpage(w, err)
+		return
+	}
+	// only a library image or a file of the RBF directory, see Resolveimage
+	filename, err = Resolveimage(filename)
+	if err != nil {
+		log.Println("Program page ", err)
+		Errorpage(w, err)
+		return
+	}
+	j.Update(func(st *Jobstate) {
+		st.Rbffile = filename
+		st.Verify = r.FormValue("verify") == "on"
+		st.Force = r.FormValue("force") == "on"
+		st.State = "ready"
+	})
+	H.Job = j.State().ID
+
 	// Open the RBF file
-	rbffilename = filename
 	log.Println("    Looking for rbf file:", filename)
 	f, err := os.Open(filename)
 	if err != nil {
-		log.Fatal(err)
+		log.Println("Could not open the file", err)
+		Errorpage(w, err)
+		return
 	}
 
 	defer func() {
 		err := f.Close()
 		if err != nil {
-			log.Fatal(err)
+			log.Println("Could not close the file", err)
 		}
 	}()
 
@@ -768,6 +867,11 @@ func filehandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</tr><tr>\n")
 	fmt.Fprintf(w, "<td align=\"right\"><b class=\"nic1\">Programming:</b> </td>")
 	fmt.Fprintf(w, "<td><div id=\"packet\" class=\"nic1\"> </div></td>")
+	fmt.Fprintf(w, "</tr><tr>\n")
+	fmt.Fprintf(w, "<td></td><td><progress id=\"bar\" max=\"100\" value=\"0\" style=\"width:100%%\"></progress></td>")
+	fmt.Fprintf(w, "</tr><tr>\n")
+	fmt.Fprintf(w, "<td align=\"right\"><b class=\"nic1\">Verify:</b> </td>")
+	fmt.Fprintf(w, "<td><div id=\"verify\" class=\"nic1\"> </div></td>")
 	fmt.Fprintf(w, "</tr>\n")
 	fmt.Fprintf(w, "</table><br/><br/>\n")
 
@@ -777,6 +881,7 @@ func filehandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<table>")
 	fmt.Fprintf(w, "<tr><td>")
 	fmt.Fprintf(w, "<form method=\"link\" action=\"/nic/\" >")
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", H.Job)
 	fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"nic\" value=\"nic\"> Return</button>")
 	fmt.Fprintf(w, "</form>")
 	fmt.Fprintf(w, "</td>")
@@ -824,6 +929,7 @@ func nositehandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</td>")
 	fmt.Fprintf(w, "<td>")
 	fmt.Fprintf(w, "<form method=\"link\" action=\"/close/\" >")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/close/"))
 	fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"quit\" value=\"quit\"> Quit</button>")
 	fmt.Fprintf(w, "</form>")
 	fmt.Fprintf(w, "</td></tr>")
@@ -858,6 +964,30 @@ func closescreenhandler(w http.ResponseWriter, r *http.Request) {
 	t3.Execute(w, "body")
 
 	fmt.Fprintf(w, "<h1>Shutting down the HPSDRProgrammer!</h1> <p> Please select the Quit or Return</p>")
+	Busytable(w, Busyjobs())
+	Quitbuttons(w, r, false)
+
+	fmt.Fprintf(w, "</body>\n")
+	fmt.Fprintf(w, "</html>\n")
+}
+
+// Warn about the boards being programmed, the server will not quit until
+// they are done.
+func Busytable(w http.ResponseWriter, busy []Jobstate) {
+	if len(busy) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "<p style=\"color:red\"><b>%d boards are being programmed.</b> Quitting now would leave them with a partial flash.</p>\n", len(busy))
+	fmt.Fprintf(w, "<table>\n")
+	for _, st := range busy {
+		fmt.Fprintf(w, "<tr><td><b>%s</b></td><td>(%s)</td><td>%s</td></tr>\n", st.Board.Board, st.Board.Macaddress, st.State)
+	}
+	fmt.Fprintf(w, "</table><br/>\n")
+}
+
+// Return and Quit buttons, with a Quit when done button once a quit was
+// refused.
+func Quitbuttons(w http.ResponseWriter, r *http.Request, refused bool) {
 	fmt.Fprintf(w, "<table>")
 	fmt.Fprintf(w, "<tr><td>")
 	fmt.Fprintf(w, "<form method=\"link\" action=\"/nic/\" >")
@@ -866,18 +996,48 @@ func closescreenhandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</td>")
 	fmt.Fprintf(w, "<td>")
 	fmt.Fprintf(w, "<form method=\"link\" action=\"/close/\" >")
-	fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"quit\" value=\"quit\"> Quit</button>")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/close/"))
+	if refused {
+		fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"quit\" value=\"done\"> Quit when done</button>")
+	} else {
+		fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"quit\" value=\"quit\"> Quit</button>")
+	}
 	fmt.Fprintf(w, "</form>")
 	fmt.Fprintf(w, "</td></tr>")
 	fmt.Fprintf(w, "</table>")
-
-	fmt.Fprintf(w, "</body>\n")
-	fmt.Fprintf(w, "</html>\n")
 }
 
-// Web handler function to stop the webserver.
+// Web handler function to stop the webserver.  It is refused while boards
+// are being programmed, quit=done waits for them instead.
 func closehandler(w http.ResponseWriter, r *http.Request) {
-	log.Fatal("Program shut down by user!")
+	if Csrfrefused(w, r, "/close/") {
+		return
+	}
+	var H Html
+	H.Version = version
+	H.Protocol = protocol
+	H.Update = update
+	H.Address = srvaddress
+	H.Port = srvport
+
+	wait := r.FormValue("quit") == "done"
+	busy := Requestquit("quit from "+r.RemoteAddr, wait)
+	Pagehead(w, H)
+
+	switch {
+	case len(busy) == 0:
+		fmt.Fprintf(w, "<h1>HPSDRProgrammer shut down.</h1> <p> You can close this window.</p>\n")
+	case wait:
+		fmt.Fprintf(w, "<h1>Shutting down when done.</h1> <p> No new boards are programmed, the HPSDRProgrammer quits when the boards below are done.</p>\n")
+		Busytable(w, busy)
+	default:
+		fmt.Fprintf(w, "<h1>Quit refused!</h1>\n")
+		Busytable(w, busy)
+		Quitbuttons(w, r, true)
+	}
+
+	fmt.Fprintf(w, "</body>\n")
+	fmt.Fprintf(w, "</html>\n")
 }
 
 func changediphandler(w http.ResponseWriter, r *http.Request) {
@@ -891,6 +1051,9 @@ func changediphandler(w http.ResponseWriter, r *http.Request) {
 	H.Port = srvport
 
 	r.ParseForm()
+	if Csrfrefused(w, r, "/changedip/") {
+		return
+	}
 
 	nic := r.FormValue("index")
 	board := r.FormValue("board")
@@ -899,18 +1062,24 @@ func changediphandler(w http.ResponseWriter, r *http.Request) {
 	ip2 := r.FormValue("ip2")
 	ip3 := r.FormValue("ip3")
 	ip4 := r.FormValue("ip4")
+	dhcp := r.FormValue("dhcp")
 
-	str := fmt.Sprintf("http://%s:%s/setip/json/?index=%s&board=%s&oldaddress=%s&ip1=%s&ip2=%s&ip3=%s&ip4=%s", srvaddress, srvport, nic, board, oadr, ip1, ip2, ip3, ip4)
+	str := fmt.Sprintf("%s://%s:%s/setip/json/?index=%s&board=%s&oldaddress=%s&ip1=%s&ip2=%s&ip3=%s&ip4=%s&dhcp=%s", srvscheme, srvaddress, srvport, nic, board, oadr, url.QueryEscape(ip1), url.QueryEscape(ip2), url.QueryEscape(ip3), url.QueryEscape(ip4), url.QueryEscape(dhcp))
 
-	res, err := http.Get(str)
+	res, err := Selfget(str)
 	if err != nil {
-		log.Fatal(err)
+		log.Println("Set IP json error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
 
 	if err != nil {
-		panic(err.Error())
+		log.Println("Read Error ", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
 
 	var msg newopenhpsdr.SetIPmessage
@@ -919,10 +1088,6 @@ func changediphandler(w http.ResponseWriter, r *http.Request) {
 	//log.Printf("String %s\n", str)
 	//log.Printf("%v\n", msg)
 
-	if r.FormValue("dhcp") == "dhcp" {
-		msg.Newaddress = "dhcp"
-	}
-
 	t, _ := template.New("head").Parse(w1p)
 	t.Execute(w, "head")
 
@@ -940,7 +1105,7 @@ func changediphandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<table>")
 	fmt.Fprintf(w, "<tr><td align=\"right\">")
 	fmt.Fprintf(w, "<b>Message:</b>")
-	fmt.Fprintf(w, "</td><td> %s", msg.Message)
+	fmt.Fprintf(w, "</td><td> %s", template.HTMLEscapeString(msg.Message))
 	fmt.Fprintf(w, "</td></tr><tr><td><b>MAC Address:</b>")
 	fmt.Fprintf(w, "</td><td> %s", msg.Macaddress)
 	fmt.Fprintf(w, "</td></tr><tr><td><b>Old Address:</b>")
@@ -948,6 +1113,9 @@ func changediphandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</td></tr><tr><td><b>New Address:</b>")
 	fmt.Fprintf(w, "</td><td> %s", msg.Newaddress)
 	fmt.Fprintf(w, "</td></tr>")
+	if msg.Warning != "" {
+		fmt.Fprintf(w, "<tr><td><b>Warning:</b></td><td> %s</td></tr>", template.HTMLEscapeString(msg.Warning))
+	}
 	fmt.Fprintf(w, "</table>")
 
 	fmt.Fprintf(w, "<table>")
@@ -987,11 +1155,9 @@ func discoverjsonhandler(w http.ResponseWriter, r *http.Request) {
 	for i := range intf {
 		if nic == int64(intf[i].Index) {
 			itr = intf[i]
-			log.Printf("Match %s  %s %s\n", nic, itr.Intname, itr.Matchname)
-
-		} else {
-
-			log.Printf("No Match %s  (%s) (%s)\n", nic, itr.Intname, itr.Matchname)
+			log.Printf("Match %d  %s %s\n", nic, itr.Intname, itr.Matchname)
+		} else if newopenhpsdr.Debugging(debug) {
+			log.Printf("No Match %d  (%s) (%s)\n", nic, intf[i].Intname, intf[i].Matchname)
 		}
 	}
 
@@ -1001,14 +1167,16 @@ func discoverjsonhandler(w http.ResponseWriter, r *http.Request) {
 	bcadr = itr.Ipv4Bcast + ":1024"
 	log.Printf("adr %s  bcadr %s\n", adr, bcadr)
 
-	str, err := newopenhpsdr.Discover(adr, bcadr, "none")
+	str, err := newopenhpsdr.DiscoverContext(r.Context(), adr, bcadr, debug)
 	if err != nil {
 		log.Println("Error ", err)
 	}
 
 	enc := json.NewEncoder(w)
 	enc.Encode(str)
-	log.Printf("%#v\n", intf)
+	if newopenhpsdr.Debugging(debug) {
+		log.Printf("%#v\n", intf)
+	}
 }
 
 // Web handler function to produce the setip json packet.
@@ -1037,28 +1205,38 @@ func setipjsonhandler(w http.ResponseWriter, r *http.Request) {
 	adr = itr.Ipv4 + ":1024"
 	bcadr = itr.Ipv4Bcast + ":1024"
 
-	str, err := newopenhpsdr.Discover(adr, bcadr, "none")
+	str, err := newopenhpsdr.DiscoverContext(r.Context(), adr, bcadr, debug)
 	if err != nil {
 		log.Println("Error ", err)
 	}
 
-	for i := 0; i < len(str); i++ {
-		if r.FormValue("board") == str[i].Macaddress {
-			st = str[i]
-		}
+	st, err = newopenhpsdr.Findboard(str, r.FormValue("board"))
+	if err != nil {
+		log.Printf("Error %v", err)
+		w.WriteHeader(Errorstatus(err))
+		json.NewEncoder(w).Encode(newopenhpsdr.SetIPmessage{Macaddress: r.FormValue("board"), Message: err.Error()})
+		return
 	}
 	if r.FormValue("dhcp") == "dhcp" {
-		nadr = "0.0.0.0"
-		log.Printf("IP changing from %s -> %s", r.FormValue("oldaddress"), r.FormValue("dhcp"))
+		nadr = "dhcp"
 	} else {
 		nadr = fmt.Sprintf("%s.%s.%s.%s", r.FormValue("ip1"), r.FormValue("ip2"), r.FormValue("ip3"), r.FormValue("ip4"))
-		log.Printf("IP changing from %s -> %s", r.FormValue("oldaddress"), nadr)
+	}
+	log.Printf("IP changing from %s -> %s", r.FormValue("oldaddress"), nadr)
+
+	nip, err := newopenhpsdr.Parseboardip(nadr)
+	if err != nil {
+		log.Printf("Error %v", err)
+		w.WriteHeader(Errorstatus(err))
+		json.NewEncoder(w).Encode(newopenhpsdr.SetIPmessage{Macaddress: st.Macaddress, Oldaddress: st.Baddress, Newaddress: nadr, Message: err.Error()})
+		return
 	}
 
-	msg, err := newopenhpsdr.Setip(adr, bcadr, st, nadr, "none")
+	msg, err := newopenhpsdr.SetipContext(r.Context(), adr, bcadr, st, nip, debug)
 	if err != nil {
 		log.Printf("Error %v", err)
-		panic(err)
+		msg.Message = err.Error()
+		w.WriteHeader(Errorstatus(err))
 	}
 
 	enc := json.NewEncoder(w)
@@ -1087,6 +1265,7 @@ func counthandler(w http.ResponseWriter, r *http.Request) {
 	H.Port = srvport
 
 	r.ParseForm()
+	H.Job = Jobfor(r).State().ID
 
 	t, _ := template.New("head").Parse(w1p)
 	t.Execute(w, "head")
@@ -1107,6 +1286,8 @@ func counthandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</tr><tr>")
 	fmt.Fprintf(w, "<td align=\"right\"><b class=\"nic1\">Programming:</b> </td>")
 	fmt.Fprintf(w, "<td><div id=\"packet\" class=\"nic1\"> </div></td>")
+	fmt.Fprintf(w, "</tr><tr>\n")
+	fmt.Fprintf(w, "<td></td><td><progress id=\"bar\" max=\"100\" value=\"0\" style=\"width:100%%\"></progress></td>")
 	fmt.Fprintf(w, "</tr>")
 	fmt.Fprintf(w, "</table>")
 
@@ -1133,7 +1314,12 @@ func uploadhandler(w http.ResponseWriter, r *http.Request) {
 	H.Address = srvaddress
 	H.Port = srvport
 
-	r.ParseForm()
+	// the form fields around the file are small
+	r.Body = http.MaxBytesReader(w, r.Body, maxupload+(64<<10))
+	r.ParseMultipartForm(32 << 20)
+	if Csrfrefused(w, r, "/upload/") {
+		return
+	}
 
 	//filename := r.FormValue("uploadfile")
 	boardtype := r.FormValue("boardtype")
@@ -1151,43 +1337,43 @@ func uploadhandler(w http.ResponseWriter, r *http.Request) {
 	t4, _ := template.New("webbanner").Parse(banner)
 	t4.Execute(w, H)
 
-	r.ParseMultipartForm(32 << 20)
+	j, err := Findjob(r.FormValue("job"))
+	if err != nil {
+		log.Println("Upload ", err)
+		Errorpage(w, err)
+		return
+	}
 	file, handler, err := r.FormFile("uploadfile")
 	if err != nil {
-		log.Println(err)
+		log.Println("Upload ", err)
+		Errorpage(w, err)
 		return
 	}
 	defer file.Close()
 
-	filestr := fmt.Sprintf("%s%s", rbffiledir, handler.Filename)
-	if _, err := os.Stat(rbffiledir); os.IsNotExist(err) {
-		os.MkdirAll(rbffiledir, os.ModePerm)
-		log.Println("Directory " + rbffiledir + " created")
-	} else {
-		log.Println("Directory " + rbffiledir + " found")
-	}
-	log.Println(filestr)
-
-	f, err := os.OpenFile(filestr, os.O_WRONLY|os.O_CREATE, 0666)
+	// kept in the firmware library under its SHA-256, see library.go
+	img, err := Storeimage(file, handler.Filename)
 	if err != nil {
-		log.Println(err)
+		log.Println("Upload ", err)
+		Errorpage(w, err)
 		return
 	}
-	defer f.Close()
-	io.Copy(f, file)
+	filestr := img.Path
 
 	// Open the RBF file
-	rbffilename = filestr
+	j.Update(func(st *Jobstate) { st.Rbffile = filestr })
 	log.Println("    Looking for rbf file:", filestr)
-	f, err = os.Open(filestr)
+	f, err := os.Open(filestr)
 	if err != nil {
-		log.Fatal(err)
+		log.Println("Could not open the file", err)
+		Errorpage(w, err)
+		return
 	}
 
 	defer func() {
 		err := f.Close()
 		if err != nil {
-			log.Fatal(err)
+			log.Println("Could not close the file", err)
 		}
 	}()
 
@@ -1209,7 +1395,7 @@ func uploadhandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "<td align=\"right\">\n")
 	fmt.Fprintf(w, "<b>Found rbf file:</b>")
 	fmt.Fprintf(w, "</td><td>")
-	fmt.Fprintf(w, "%s", rbffilename)
+	fmt.Fprintf(w, "%s", filestr)
 	fmt.Fprintf(w, "</td></tr>")
 	fmt.Fprintf(w, "<td align=\"right\">\n")
 	fmt.Fprintf(w, "<b>Size rbf file:</b>")
@@ -1226,11 +1412,35 @@ func uploadhandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</td><td>")
 	fmt.Fprintf(w, "%d", packets)
 	fmt.Fprintf(w, "</td></tr>")
+	fmt.Fprintf(w, "<tr><td align=\"right\"><b>Library SHA-256:</b></td><td><tt>%s</tt></td></tr>\n", img.Sha256)
+
+	// show the manifest next to the RBF file, and whether it matches
+	m, found, err := newopenhpsdr.Checkmanifest(filestr, j.State().Board)
+	if found {
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>Manifest:</b></td><td>%s</td></tr>\n", template.HTMLEscapeString(m.Path))
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>Manifest board:</b></td><td>%s</td></tr>\n", template.HTMLEscapeString(m.Board))
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>Manifest firmware:</b></td><td>%s</td></tr>\n", template.HTMLEscapeString(m.Firmware))
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>SHA-256:</b></td><td>%s</td></tr>\n", template.HTMLEscapeString(m.Sha256))
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>Release notes:</b></td><td><pre>%s</pre></td></tr>\n", template.HTMLEscapeString(m.Notes))
+	} else {
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>Manifest:</b></td><td>none</td></tr>\n")
+	}
+	if err != nil {
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>Manifest check:</b></td><td style=\"color:red\">%s</td></tr>\n", template.HTMLEscapeString(err.Error()))
+	} else if found {
+		fmt.Fprintf(w, "<tr><td align=\"right\"><b>Manifest check:</b></td><td>Passed</td></tr>\n")
+	}
 	fmt.Fprintf(w, "</table><br/><br/>\n")
 	fmt.Fprintf(w, "<form action=\"/file/\">")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/file/"))
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", j.State().ID)
 	fmt.Fprintf(w, "<input type=\"hidden\" name=\"index\" value=%s>\n", intf)
 	fmt.Fprintf(w, "<input type=\"hidden\" name=\"boardtype\" value=%s>\n", boardtype)
-	fmt.Fprintf(w, "<input type=\"hidden\" name=\"img\" value=%s>\n", filestr)
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"img\" value=\"%s\">\n", img.Sha256)
+	fmt.Fprintf(w, "<label><input type=\"checkbox\" name=\"verify\" checked> Verify the board after programming</label><br/>\n")
+	if err != nil {
+		fmt.Fprintf(w, "<label><input type=\"checkbox\" name=\"force\"> Program even though the manifest does not match</label><br/>\n")
+	}
 	fmt.Fprintf(w, "<input class=\"btn\" type=\"submit\" value=\"Program\">")
 	fmt.Fprintf(w, "</form>")
 	fmt.Fprintf(w, "<br/>")
@@ -1240,75 +1450,234 @@ func uploadhandler(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// Progress frame sent as JSON over the /counter/ websocket
+type Progressframe struct {
+	State   string  `json:"state"` // checking, erasing, erased, programming, programmed, verifying, done, failed
+	Erase   string  `json:"erase"` // text for the erase row
+	Program string  `json:"program"`
+	Verify  string  `json:"verify"`
+	Percent float64 `json:"percent"`
+	Block   uint32  `json:"block"` // blocks acked
+	Blocks  uint32  `json:"blocks"`
+	Retries int     `json:"retries"`
+	Rate    float64 `json:"rate"` // bytes per second
+	Eta     float64 `json:"eta"`  // seconds left
+	Seconds float64 `json:"seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Update the frame from a progress event of the library.
+func (fr *Progressframe) update(p newopenhpsdr.Progress) {
+	switch p.Kind {
+	case newopenhpsdr.Erasestarted:
+		fr.State = "erasing"
+		fr.Erase = fmt.Sprintf("Erase started %4.1f seconds", p.Elapsed.Seconds())
+	case newopenhpsdr.Erasefinished:
+		fr.State = "erased"
+		fr.Erase = fmt.Sprintf("Erase done %4.1f seconds", p.Elapsed.Seconds())
+	case newopenhpsdr.Blockacked:
+		fr.State = "programming"
+		fr.Block = p.Block + 1
+		fr.Blocks = p.Blocks
+		fr.Retries = p.Retries
+		fr.Rate = p.Rate
+		fr.Eta = p.Eta.Seconds()
+	case newopenhpsdr.Blockretry:
+		fr.Retries = p.Retries
+	case newopenhpsdr.Programdone:
+		fr.State = "programmed"
+		fr.Block = p.Blocks
+		fr.Blocks = p.Blocks
+		fr.Retries = p.Retries
+		fr.Eta = 0
+	}
+	if fr.Blocks > 0 {
+		fr.Percent = 100 * float64(fr.Block) / float64(fr.Blocks)
+	}
+	switch fr.State {
+	case "programming":
+		fr.Program = fmt.Sprintf("Block %d of %d (%.0f%%), %d retries, %.0f bytes/s, %.1f seconds left", fr.Block, fr.Blocks, fr.Percent, fr.Retries, fr.Rate, fr.Eta)
+	case "programmed":
+		fr.Program = fmt.Sprintf("Programming done, %d blocks, %d retries", fr.Blocks, fr.Retries)
+	}
+}
+
+// Mark the frame failed, the error goes in the row of the step that failed.
+func (fr *Progressframe) fail(err error) {
+	fr.Error = err.Error()
+	switch fr.State {
+	case "checking":
+		fr.Erase = "Not started: " + fr.Error
+	case "erasing":
+		fr.Erase = "Erase failed: " + fr.Error
+	case "verifying":
+		fr.Verify = "Failed: " + fr.Error
+	default:
+		fr.Program = "Failed: " + fr.Error
+	}
+	fr.State = "failed"
+}
+
+// Websocket handler starting the erase and program of the board of a job
+// and sending its Progressframe until the run ends.  The run is the same
+// as that of Startjob, it goes on when the browser goes away, so closing
+// or reloading the page never leaves a half written board.
 func sensorhandler(ws *websocket.Conn) {
-	var m chan int
-	var mnum int
-	var msge string
-	var seccount int
-	var erasing bool
-	m = make(chan int)
-	seccount = -1
-
-	go func() {
-		readsensor(m, rbffiledir, rbffilename)
-	}()
+	var fr Progressframe
+	start := time.Now()
+	fr.State = "checking"
+	fr.Erase = "Pending"
+	fr.Program = "Pending"
+
+	// the job ID is in the websocket URL of the program page
+	j, err := Findjob(ws.Request().FormValue("job"))
+	if err != nil {
+		log.Println("Program run ", err)
+		fr.fail(err)
+		websocket.JSON.Send(ws, fr)
+		return
+	}
+	// only the program page armed by the Program form may start a run, a
+	// reload or another site opening the websocket does not
+	armed := false
+	j.Update(func(st *Jobstate) {
+		if st.State == "ready" {
+			st.State = "queued"
+			armed = true
+		}
+	})
+	if !armed {
+		log.Printf("Program run %s refused in state %s\n", j.State().ID, j.State().State)
+		fr.fail(errors.New("job not ready, select the RBF file and press Program again"))
+		websocket.JSON.Send(ws, fr)
+		return
+	}
+	Startjob(j)
 
-	for {
-		select {
-		case mnum = <-m:
-			if mnum == 2001 {
-				erasing = true
-				msge = fmt.Sprintf("Erase Started 0 seconds, Pending")
-			} else if mnum == 2999 {
-				erasing = false
-				seccount = 0
-				msge = fmt.Sprintf("Erase Done, Pending")
-			} else if mnum == 1003 {
-				msge = fmt.Sprintf("Erase Done, Programming Started 0 Seconds")
-			} else if mnum == 1004 {
-				msge = fmt.Sprintf("Erase Done, Programmming Done")
-			}
-		default:
-			if erasing {
-				seccount += 1
-				msge = fmt.Sprintf("Erase %4.1f seconds, Pending", float64(seccount)/100.0)
-			} else {
-				seccount += 1
-				msge = fmt.Sprintf("Erase Done, Programming Started %4.1f seconds", float64(seccount)/100.0)
-			}
+	tick := time.NewTicker(250 * time.Millisecond)
+	defer tick.Stop()
+
+	for range tick.C {
+		fr = j.State().Frame
+		if fr.State == "verifying" {
+			fr.Verify = fmt.Sprintf("Verifying %4.1f seconds", time.Since(start).Seconds())
 		}
-		websocket.Message.Send(ws, msge)
-		time.Sleep(10 * time.Millisecond)
-		if mnum == 1004 {
-			break
+		if err := websocket.JSON.Send(ws, fr); err != nil {
+			log.Printf("Websocket closed, job %s goes on: %v\n", j.State().ID, err)
+			return
+		}
+		if (fr.State == "done") || (fr.State == "failed") {
+			return
 		}
 	}
 }
 
-func readsensor(m chan int, rdir string, rfile string) {
-	m <- 2001
+// Check, erase, program and optionally verify the board of a job,
+// reporting progress to obs.  verify is called when the verification
+// starts, the one line verification summary is returned.  A board that
+// does not verify fails the job with ErrVerifyFailed.
+func readsensor(ctx context.Context, obs newopenhpsdr.Observer, verify func(), st Jobstate) (string, error) {
+	bd := st.Board
 
-	err := newopenhpsdr.Erase(crtbd.Pcaddress, crtbd, "none")
+	// check the file before the flash is erased, a failed program
+	// leaves the radio unbootable
+	_, err := newopenhpsdr.Validaterbf(st.Rbffile, bd)
 	if err != nil {
-		log.Fatal(err)
-	} else {
-		m <- 2999
+		log.Println("RBF check failed ", err)
+		return "", err
+	}
+	mf, found, err := newopenhpsdr.Checkmanifest(st.Rbffile, bd)
+	if err != nil && !st.Force {
+		log.Println("Manifest check failed ", err)
+		return "", err
+	}
 
-		//fullfilename := rdir + rfile
-		log.Printf("Reading RBF file %s\n", rbffilename)
-		m <- 1003
-		err = newopenhpsdr.Program(crtbd.Pcaddress, crtbd, rbffilename, "none")
-		if err != nil {
-			log.Fatal(err)
-		}
-		m <- 1004
+	err = newopenhpsdr.EraseProgress(ctx, bd.Pcaddress, bd, debug, obs)
+	if err != nil {
+		log.Println("Erase failed ", err)
+		return "", err
+	}
+
+	log.Printf("Reading RBF file %s\n", st.Rbffile)
+	err = newopenhpsdr.ProgramProgress(ctx, bd.Pcaddress, bd, st.Rbffile, st.Force, debug, obs)
+	if err != nil {
+		log.Println("Program failed ", err)
+		return "", err
 	}
+
+	if !st.Verify {
+		return "", nil
+	}
+	verify()
+	exp := newopenhpsdr.Rbfexpect(st.Rbffile)
+	if found && (mf.Firmware != "") {
+		exp.Firmware = mf.Firmware
+	}
+	bcadr := "255.255.255.255:1024"
+	if st.Targets != "" {
+		bcadr = st.Targets
+	}
+	res, err := newopenhpsdr.VerifyContext(ctx, bd.Pcaddress, bcadr, bd.Macaddress, exp, newopenhpsdr.Rebootdelay, debug)
+	if err != nil {
+		log.Println("Verify failed ", err)
+		return "", fmt.Errorf("%w: %s", err, Verifytext(res))
+	}
+	return Verifytext(res), nil
+}
+
+// One line summary of a verification for the progress page.
+func Verifytext(res newopenhpsdr.Verifyresult) string {
+	var strs []string
+	for _, c := range res.Checks {
+		strs = append(strs, fmt.Sprintf("%s want %s got %s", c.Field, c.Want, c.Got))
+	}
+	str := "Passed"
+	if !res.Passed {
+		str = "Failed"
+	}
+	if len(strs) > 0 {
+		str = str + ": " + strings.Join(strs, "; ")
+	}
+	return str + " (" + res.Message + ")"
+}
+
+// Map a newopenhpsdr error to an HTTP status code.
+func Errorstatus(err error) int {
+	switch {
+	case errors.Is(err, newopenhpsdr.ErrBoardNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, newopenhpsdr.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, newopenhpsdr.ErrFileInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, newopenhpsdr.ErrAddressInvalid):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// Write an error message to a page whose header is already sent.
+func Errorpage(w http.ResponseWriter, err error) {
+	fmt.Fprintf(w, "<h2>Error</h2> <p>%s</p>\n", template.HTMLEscapeString(err.Error()))
+	fmt.Fprintf(w, "<form method=\"link\" action=\"/nic/\" >")
+	fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"nic\" value=\"nic\"> Return</button>")
+	fmt.Fprintf(w, "</form>")
+	fmt.Fprintf(w, "</body>\n")
+	fmt.Fprintf(w, "</html>\n")
 }
 
 // Main function for the HPSDRProgrammer_web program.
 func main() {
 	strbfdir := flag.String("setRBFdir", "none", "Select the RBF Directory")
 	address := flag.String("address", "localhost", "Select server IP address")
+	parallel := flag.Int("parallel", newopenhpsdr.Batchparallel, "Boards programmed at once")
+	password := flag.String("password", os.Getenv("HPSDR_PASSWORD"), "Login password of the web pages, default $HPSDR_PASSWORD")
+	token := flag.String("token", os.Getenv("HPSDR_TOKEN"), "Bearer token of the API, also a login password, default $HPSDR_TOKEN")
+	usetls := flag.Bool("tls", false, "Serve https, with a self-signed certificate in the RBF directory unless -cert and -key are given")
+	certfile := flag.String("cert", "", "TLS certificate file, implies -tls")
+	keyfile := flag.String("key", "", "TLS key file, implies -tls")
+	maxup := flag.Int64("maxupload", maxupload, "Largest RBF file accepted, in bytes")
+	db := flag.String("debug", "none", "Turn debugging and output type, (none, dec, hex)")
 
 	flag.Parse()
 
@@ -1335,17 +1704,33 @@ func main() {
 	}
 
 	log.Printf("RBF directory %s", rbffiledir)
+	maxupload = *maxup
+	debug = *db
+	authpassword = *password
+	authtoken = *token
+	if Authrequired() {
+		log.Println("Login required")
+	}
+	if *parallel > 0 {
+		jobslots = make(chan struct{}, *parallel)
+	}
 
-	log.Println("Listening ...")
+	srvaddress = *address
 
-	if *address == "localhost" {
-		log.Println("Point your web browser to: http://localhost:8228/intro/ ")
-		srvaddress = *address
-	} else {
-		log.Printf("Point your web browser to: http://%s:8228/intro/ ", *address)
-		srvaddress = *address
+	var tlscfg *tls.Config
+	if *usetls || (*certfile != "") || (*keyfile != "") {
+		var err error
+		tlscfg, err = Loadtls(*certfile, *keyfile)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
+	log.Println("Listening ...")
+	log.Printf("Point your web browser to: %s://%s:%s/intro/ ", srvscheme, srvaddress, srvport)
+
+	// the json pages below are used by the web pages themselves, other
+	// programs should use the REST API under /api/v1/, see api.go
 	http.HandleFunc("/nic/json/", nicjsonhandler)
 	http.HandleFunc("/setip/json/", setipjsonhandler)
 	http.HandleFunc("/discover/json/", discoverjsonhandler)
@@ -1362,12 +1747,26 @@ func main() {
 	http.HandleFunc("/closescreen/", closescreenhandler)
 	http.HandleFunc("/close/", closehandler)
 	http.HandleFunc("/nosite/", nositehandler)
-	http.Handle("/counter/", websocket.Handler(sensorhandler))
+	http.Handle("/counter/", websocket.Server{Handler: sensorhandler, Handshake: Checkorigin})
 	http.HandleFunc("/count/", counthandler)
 	http.HandleFunc("/intro/", introhandler)
+	http.HandleFunc("/batch/", batchhandler)
+	http.HandleFunc("/batch/start/", batchstarthandler)
+	http.HandleFunc("/batch/status/", batchstatushandler)
+	http.HandleFunc("/jobs/json/", jobsjsonhandler)
+	http.HandleFunc("/library/", libraryhandler)
+	http.HandleFunc("/library/delete/", librarydeletehandler)
+	http.HandleFunc(apiprefix, apihandler)
+	http.HandleFunc("/login/", loginhandler)
+	http.HandleFunc("/logout/", logouthandler)
 	http.Handle("/js/", http.FileServer(http.Dir(".")))
 
-	lsnadr := fmt.Sprintf(":%s", srvport)
+	// listen on the address asked for only, not on every interface
+	lsnadr := net.JoinHostPort(srvaddress, srvport)
+	if !Authrequired() && !Loopback(srvaddress) {
+		log.Printf("Warning: no -password or -token, anyone who can reach %s can program the radios", lsnadr)
+	}
 
-	log.Fatal(http.ListenAndServe(lsnadr, nil))
+	srv := &http.Server{Addr: lsnadr, Handler: Guard(http.DefaultServeMux), TLSConfig: tlscfg}
+	Serve(srv)
 }
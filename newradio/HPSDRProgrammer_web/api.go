@@ -0,0 +1,487 @@
+// Versioned REST API of the web programmer
+// GPL2
+//
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Prefix of every API route
+const apiprefix string = "/api/v1/"
+
+// Discoveries kept for GET, the oldest is dropped first.
+const apidiscoveries int = 32
+
+// Error body of every failed API call
+type Apierror struct {
+	Status int    `json:"status"`
+	Code   string `json:"code"` // badrequest, unauthorized, forbidden, notfound, timeout, conflict, file, method, unavailable, failed
+	Error  string `json:"error"`
+}
+
+// Body of POST discoveries, Targets unicasts to a comma separated list of
+// board addresses instead of broadcasting on the interface.
+type Discoveryrequest struct {
+	Index   int    `json:"index"`
+	Targets string `json:"targets,omitempty"`
+}
+
+// One discovery run
+type Discovery struct {
+	ID      string                    `json:"id"`
+	Index   int                       `json:"index"`
+	Intface string                    `json:"intface"`
+	Targets string                    `json:"targets,omitempty"`
+	Time    time.Time                 `json:"time"`
+	Boards  []newopenhpsdr.Hpsdrboard `json:"boards"`
+}
+
+// Body of PUT boards/{mac}/ip, Address "dhcp" returns the board to DHCP.
+type Setiprequest struct {
+	Index   int    `json:"index"`
+	Targets string `json:"targets,omitempty"`
+	Address string `json:"address"`
+}
+
+// Body of POST jobs.  Rbf is the sha256 of a library image, as returned
+// by POST firmware, or a bare file name of the library or the RBF
+// directory, never a path, see Resolveimage.
+type Jobrequest struct {
+	Index   int    `json:"index"`
+	Targets string `json:"targets,omitempty"`
+	Mac     string `json:"mac"`
+	Rbf     string `json:"rbf"`
+	Verify  bool   `json:"verify"`
+	Force   bool   `json:"force"`
+}
+
+// An image of the firmware library, the reply of POST firmware
+type Firmware struct {
+	Name     string                 `json:"name"`
+	Path     string                 `json:"path"`
+	Sha256   string                 `json:"sha256"` // use as Jobrequest.Rbf
+	Rbf      newopenhpsdr.Rbfinfo   `json:"rbf"`
+	Board    string                 `json:"board,omitempty"`    // board type the file name is for
+	Firmware string                 `json:"firmware,omitempty"` // version from the name or the manifest
+	Uploaded time.Time              `json:"uploaded"`
+	Manifest *newopenhpsdr.Manifest `json:"manifest,omitempty"`
+}
+
+var (
+	discmu      sync.Mutex
+	discoveries []Discovery
+)
+
+// Write v as the JSON reply with the status.
+func Apireply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.Encode(v)
+}
+
+// Write an error reply.  Errors of newopenhpsdr get their status from
+// Errorstatus, other errors get the status given.
+func Apifail(w http.ResponseWriter, status int, err error) {
+	if (status == 0) || (status == http.StatusInternalServerError) {
+		status = Errorstatus(err)
+	}
+	if errors.Is(err, ErrBoardBusy) {
+		status = http.StatusConflict
+	}
+	if errors.Is(err, ErrQuitting) {
+		status = http.StatusServiceUnavailable
+	}
+	code := "failed"
+	switch status {
+	case http.StatusBadRequest:
+		code = "badrequest"
+		if errors.Is(err, newopenhpsdr.ErrFileInvalid) {
+			code = "file"
+		}
+	case http.StatusUnauthorized:
+		code = "unauthorized"
+	case http.StatusForbidden:
+		code = "forbidden"
+	case http.StatusNotFound:
+		code = "notfound"
+	case http.StatusGatewayTimeout:
+		code = "timeout"
+	case http.StatusConflict:
+		code = "conflict"
+	case http.StatusMethodNotAllowed:
+		code = "method"
+	case http.StatusServiceUnavailable:
+		code = "unavailable"
+	}
+	log.Printf("API error %d %s: %v\n", status, code, err)
+	Apireply(w, status, Apierror{Status: status, Code: code, Error: err.Error()})
+}
+
+// Refuse a method the route does not have.
+func Apimethod(w http.ResponseWriter, r *http.Request, allow ...string) {
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	Apifail(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed, use %s", r.Method, strings.Join(allow, ", ")))
+}
+
+// Decode the JSON body into v, unknown fields are refused.
+func Apibody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("request body: %v", err)
+	}
+	return nil
+}
+
+// Discover on the interface with the index, or at the targets.
+func Apidiscover(ctx context.Context, index int, targets string) (itr newopenhpsdr.Intface, str []newopenhpsdr.Hpsdrboard, er error) {
+	if targets == "" {
+		return Discoverindex(ctx, index)
+	}
+	for _, intf := range newopenhpsdr.Interfaces() {
+		if intf.Index == index {
+			itr = intf
+		}
+	}
+	adr := "0.0.0.0:0"
+	if itr.Ipv4 != "" {
+		adr = itr.Ipv4 + ":0"
+	}
+	str, er = newopenhpsdr.DiscoverTargets(ctx, adr, newopenhpsdr.Targetaddrs(targets), debug)
+	return itr, str, er
+}
+
+// Route the /api/v1/ requests.
+//
+//	GET  openapi.json         OpenAPI document of these routes
+//	GET  interfaces           network interfaces of the server
+//	GET  discoveries          recent discoveries
+//	POST discoveries          discover now, Discoveryrequest
+//	GET  discoveries/{id}     one discovery
+//	PUT  boards/{mac}/ip      set the IP address of a board, Setiprequest
+//	GET  firmware             images of the firmware library
+//	POST firmware             upload an RBF file to the library, multipart field file
+//	GET  firmware/{sha256}    one library image
+//	DELETE firmware/{sha256}  remove a library image
+//	GET  jobs                 every programming job
+//	POST jobs                 erase and program a board, Jobrequest
+//	GET  jobs/{id}            status of one job
+func apihandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, apiprefix), "/")
+	parts := strings.Split(path, "/")
+	log.Printf("API %s %s\n", r.Method, r.URL.Path)
+
+	switch {
+	case path == "openapi.json":
+		openapihandler(w, r)
+
+	case path == "interfaces":
+		if r.Method != http.MethodGet {
+			Apimethod(w, r, http.MethodGet)
+			return
+		}
+		Apireply(w, http.StatusOK, newopenhpsdr.Interfaces())
+
+	case path == "discoveries":
+		switch r.Method {
+		case http.MethodGet:
+			discmu.Lock()
+			dl := append([]Discovery{}, discoveries...)
+			discmu.Unlock()
+			Apireply(w, http.StatusOK, dl)
+		case http.MethodPost:
+			apipostdiscovery(w, r)
+		default:
+			Apimethod(w, r, http.MethodGet, http.MethodPost)
+		}
+
+	case (len(parts) == 2) && (parts[0] == "discoveries"):
+		if r.Method != http.MethodGet {
+			Apimethod(w, r, http.MethodGet)
+			return
+		}
+		discmu.Lock()
+		defer discmu.Unlock()
+		for _, d := range discoveries {
+			if d.ID == parts[1] {
+				Apireply(w, http.StatusOK, d)
+				return
+			}
+		}
+		Apifail(w, http.StatusNotFound, fmt.Errorf("no discovery %q", parts[1]))
+
+	case (len(parts) == 3) && (parts[0] == "boards") && (parts[2] == "ip"):
+		if r.Method != http.MethodPut {
+			Apimethod(w, r, http.MethodPut)
+			return
+		}
+		apiputip(w, r, parts[1])
+
+	case path == "firmware":
+		switch r.Method {
+		case http.MethodGet:
+			fl := []Firmware{}
+			for _, img := range Libraryimages() {
+				fw, err := Apifirmware(img)
+				if err != nil {
+					log.Printf("API firmware %s: %v\n", img.Path, err)
+					continue
+				}
+				fl = append(fl, fw)
+			}
+			Apireply(w, http.StatusOK, fl)
+		case http.MethodPost:
+			apipostfirmware(w, r)
+		default:
+			Apimethod(w, r, http.MethodGet, http.MethodPost)
+		}
+
+	case (len(parts) == 2) && (parts[0] == "firmware"):
+		apifirmwareimage(w, r, parts[1])
+
+	case path == "jobs":
+		switch r.Method {
+		case http.MethodGet:
+			jl := Joblist()
+			sort.Slice(jl, func(i, k int) bool { return jl[i].Created.Before(jl[k].Created) })
+			Apireply(w, http.StatusOK, jl)
+		case http.MethodPost:
+			apipostjob(w, r)
+		default:
+			Apimethod(w, r, http.MethodGet, http.MethodPost)
+		}
+
+	case (len(parts) == 2) && (parts[0] == "jobs"):
+		if r.Method != http.MethodGet {
+			Apimethod(w, r, http.MethodGet)
+			return
+		}
+		j, err := Findjob(parts[1])
+		if err != nil {
+			Apifail(w, http.StatusNotFound, err)
+			return
+		}
+		Apireply(w, http.StatusOK, j.State())
+
+	default:
+		Apifail(w, http.StatusNotFound, fmt.Errorf("no route %s", r.URL.Path))
+	}
+}
+
+// POST discoveries
+func apipostdiscovery(w http.ResponseWriter, r *http.Request) {
+	var req Discoveryrequest
+	if err := Apibody(r, &req); err != nil {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+	itr, str, err := Apidiscover(r.Context(), req.Index, req.Targets)
+	if err != nil && !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+
+	d := Discovery{ID: Newid(), Index: itr.Index, Intface: itr.Intname, Targets: req.Targets, Time: time.Now(), Boards: str}
+	if d.Boards == nil {
+		d.Boards = []newopenhpsdr.Hpsdrboard{}
+	}
+	discmu.Lock()
+	discoveries = append(discoveries, d)
+	if len(discoveries) > apidiscoveries {
+		discoveries = discoveries[len(discoveries)-apidiscoveries:]
+	}
+	discmu.Unlock()
+
+	w.Header().Set("Location", apiprefix+"discoveries/"+d.ID)
+	Apireply(w, http.StatusCreated, d)
+}
+
+// PUT boards/{mac}/ip
+func apiputip(w http.ResponseWriter, r *http.Request, mac string) {
+	var req Setiprequest
+	if err := Apibody(r, &req); err != nil {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Address == "" {
+		Apifail(w, http.StatusBadRequest, errors.New("address is required, an IPv4 address or dhcp"))
+		return
+	}
+	nip, err := newopenhpsdr.Parseboardip(req.Address)
+	if err != nil {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+
+	itr, str, err := Apidiscover(r.Context(), req.Index, req.Targets)
+	if err != nil && !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+	st, err := newopenhpsdr.Findboard(str, mac)
+	if err != nil {
+		Apifail(w, http.StatusNotFound, err)
+		return
+	}
+
+	bcadr := itr.Ipv4Bcast + ":" + newopenhpsdr.Boardport
+	if req.Targets != "" {
+		bcadr = req.Targets
+	}
+	msg, err := newopenhpsdr.SetipContext(r.Context(), st.Pcaddress, bcadr, st, nip, debug)
+	if err != nil {
+		Apifail(w, 0, err)
+		return
+	}
+	Apireply(w, http.StatusOK, msg)
+}
+
+// POST firmware
+func apipostfirmware(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxupload+(64<<10))
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		Apifail(w, http.StatusBadRequest, fmt.Errorf("multipart body: %v", err))
+		return
+	}
+	file, handler, err := r.FormFile("file")
+	if err != nil {
+		Apifail(w, http.StatusBadRequest, fmt.Errorf("field file: %v", err))
+		return
+	}
+	defer file.Close()
+
+	img, err := Storeimage(file, handler.Filename)
+	if err != nil {
+		Apifail(w, http.StatusInternalServerError, err)
+		return
+	}
+	fw, err := Apifirmware(img)
+	if err != nil {
+		Apifail(w, http.StatusInternalServerError, err)
+		return
+	}
+	log.Printf("API firmware %s %d bytes sha256 %s\n", fw.Path, fw.Rbf.Size, fw.Sha256)
+	Apireply(w, http.StatusCreated, fw)
+}
+
+// Reply for a library image, with the file checked again.
+func Apifirmware(img Libraryimage) (fw Firmware, er error) {
+	fw.Name = img.Name
+	fw.Path = img.Path
+	fw.Sha256 = img.Sha256
+	fw.Board = img.Board
+	fw.Firmware = img.Firmware
+	fw.Uploaded = img.Uploaded
+	fw.Manifest = img.Manifest
+	fw.Rbf, er = newopenhpsdr.Validaterbf(img.Path, newopenhpsdr.Hpsdrboard{})
+	return fw, er
+}
+
+// GET and DELETE firmware/{sha256}
+func apifirmwareimage(w http.ResponseWriter, r *http.Request, sum string) {
+	switch r.Method {
+	case http.MethodGet:
+		img, err := Findimage(sum)
+		if err != nil {
+			Apifail(w, http.StatusNotFound, err)
+			return
+		}
+		fw, err := Apifirmware(img)
+		if err != nil {
+			Apifail(w, http.StatusInternalServerError, err)
+			return
+		}
+		Apireply(w, http.StatusOK, fw)
+	case http.MethodDelete:
+		err := Deleteimage(sum)
+		switch {
+		case errors.Is(err, ErrNoImage):
+			Apifail(w, http.StatusNotFound, err)
+		case errors.Is(err, ErrImageInUse):
+			Apifail(w, http.StatusConflict, err)
+		case err != nil:
+			Apifail(w, http.StatusInternalServerError, err)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		Apimethod(w, r, http.MethodGet, http.MethodDelete)
+	}
+}
+
+// POST jobs
+func apipostjob(w http.ResponseWriter, r *http.Request) {
+	var req Jobrequest
+	if err := Apibody(r, &req); err != nil {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+	if (req.Mac == "") || (req.Rbf == "") {
+		Apifail(w, http.StatusBadRequest, errors.New("mac and rbf are required"))
+		return
+	}
+	if Quitting() {
+		Apifail(w, 0, ErrQuitting)
+		return
+	}
+	rbf, err := Resolveimage(req.Rbf)
+	if errors.Is(err, ErrNoImage) {
+		Apifail(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		Apifail(w, 0, err)
+		return
+	}
+
+	itr, str, err := Apidiscover(r.Context(), req.Index, req.Targets)
+	if err != nil && !errors.Is(err, newopenhpsdr.ErrTimeout) {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+	bd, err := newopenhpsdr.Findboard(str, req.Mac)
+	if err != nil {
+		Apifail(w, http.StatusNotFound, err)
+		return
+	}
+
+	// refuse a file that cannot be used now, rather than in a failed job
+	if !req.Force && !newopenhpsdr.Rbfforboard(bd, rbf) {
+		Apifail(w, http.StatusBadRequest, &newopenhpsdr.FileError{Filename: rbf, Err: fmt.Errorf("name does not match the board %s", bd.Board)})
+		return
+	}
+	if _, err := newopenhpsdr.Validaterbf(rbf, bd); err != nil {
+		Apifail(w, http.StatusBadRequest, err)
+		return
+	}
+
+	j := Newjob()
+	j.Update(func(st *Jobstate) {
+		st.Index = itr.Index
+		st.Intface = itr.Intname
+		st.Targets = req.Targets
+		st.Board = bd
+		st.Rbffile = rbf
+		st.Verify = req.Verify
+		st.Force = req.Force
+	})
+	Startjob(j)
+
+	w.Header().Set("Location", apiprefix+"jobs/"+j.State().ID)
+	Apireply(w, http.StatusAccepted, j.State())
+}
@@ -0,0 +1,326 @@
+// Login, sessions and CSRF tokens of the web programmer
+// GPL2
+//
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Session cookie and lifetimes
+const (
+	sessioncookie string        = "hpsdrsession"
+	sessionlife   time.Duration = 12 * time.Hour
+	csrflife      time.Duration = 2 * time.Hour
+)
+
+// Login settings, set from the flags at start up.  Without a password and
+// a token every browser is logged in, the CSRF checks still apply.
+var (
+	authpassword string // login password for the web pages
+	authtoken    string // bearer token for the API, also accepted as password
+)
+
+// Token the server uses for the json pages it reads from itself.
+var selftoken string
+
+// Key signing the CSRF tokens, new at every start.
+var csrfkey []byte
+
+// One browser session
+type session struct {
+	id      string
+	authed  bool
+	expires time.Time
+}
+
+type sessionkey struct{}
+
+var (
+	sessmu   sync.Mutex
+	sessions = make(map[string]*session)
+)
+
+// Errors of the checks
+var (
+	ErrCsrf  = errors.New("form expired or not sent by this server, reload the page")
+	ErrLogin = errors.New("login required")
+)
+
+func init() {
+	csrfkey = make([]byte, 32)
+	if _, err := rand.Read(csrfkey); err != nil {
+		log.Fatal("No random numbers for the CSRF key ", err)
+	}
+	selftoken = Newid() + Newid()
+}
+
+// Report whether a password or token has to be given.
+func Authrequired() bool {
+	return (authpassword != "") || (authtoken != "")
+}
+
+// Report whether the server address is reachable from this computer only.
+func Loopback(address string) bool {
+	if address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return (ip != nil) && ip.IsLoopback()
+}
+
+// Compare secrets in constant time.
+func secretequal(a string, b string) bool {
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+// Report whether the login form value is the password or the token.
+func Checkpassword(pw string) bool {
+	if pw == "" {
+		return false
+	}
+	return ((authpassword != "") && secretequal(pw, authpassword)) || ((authtoken != "") && secretequal(pw, authtoken))
+}
+
+// Report whether the request carries the API token, or the token of the
+// server itself, as Authorization: Bearer.
+func Bearer(r *http.Request) bool {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, "Bearer ") {
+		return false
+	}
+	t := strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	return secretequal(t, selftoken) || ((authtoken != "") && secretequal(t, authtoken))
+}
+
+// Session of the request, a new one with its cookie when the browser has
+// none or it expired.  Without login settings a new session is logged in.
+func Session(w http.ResponseWriter, r *http.Request) *session {
+	now := time.Now()
+	if c, err := r.Cookie(sessioncookie); err == nil {
+		sessmu.Lock()
+		s, ok := sessions[c.Value]
+		sessmu.Unlock()
+		if ok && now.Before(s.expires) {
+			return s
+		}
+	}
+	return Newsession(w, !Authrequired())
+}
+
+// Start a session and send its cookie.
+func Newsession(w http.ResponseWriter, authed bool) *session {
+	now := time.Now()
+	s := &session{id: Newid() + Newid(), authed: authed, expires: now.Add(sessionlife)}
+	sessmu.Lock()
+	for id, o := range sessions {
+		if now.After(o.expires) {
+			delete(sessions, id)
+		}
+	}
+	sessions[s.id] = s
+	sessmu.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: sessioncookie, Value: s.id, Path: "/", Expires: s.expires, HttpOnly: true, Secure: srvscheme == "https", SameSite: http.SameSiteLaxMode})
+	return s
+}
+
+// Session stored in the request by Guard.
+func requestsession(r *http.Request) *session {
+	s, _ := r.Context().Value(sessionkey{}).(*session)
+	if s == nil {
+		s = &session{}
+	}
+	return s
+}
+
+// CSRF token for a form sent to action, good for csrflife and only for
+// the session of the request.
+func Csrftoken(r *http.Request, action string) string {
+	exp := time.Now().Add(csrflife).Unix()
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(exp))
+	return base64.RawURLEncoding.EncodeToString(append(b, csrfmac(requestsession(r).id, action, exp)...))
+}
+
+func csrfmac(sid string, action string, exp int64) []byte {
+	m := hmac.New(sha256.New, csrfkey)
+	fmt.Fprintf(m, "%s\n%s\n%d", sid, action, exp)
+	return m.Sum(nil)[:16]
+}
+
+// Hidden form field with the CSRF token for action.
+func Csrffield(r *http.Request, action string) string {
+	return fmt.Sprintf("<input type=\"hidden\" name=\"csrf\" value=\"%s\">\n", Csrftoken(r, action))
+}
+
+// Check the csrf form value of a request to action.
+func Csrfcheck(r *http.Request, action string) error {
+	b, err := base64.RawURLEncoding.DecodeString(r.FormValue("csrf"))
+	if err != nil || len(b) != 8+16 {
+		return ErrCsrf
+	}
+	exp := int64(binary.BigEndian.Uint64(b[:8]))
+	if time.Now().Unix() > exp {
+		return ErrCsrf
+	}
+	if !hmac.Equal(b[8:], csrfmac(requestsession(r).id, action, exp)) {
+		return ErrCsrf
+	}
+	return nil
+}
+
+// Refuse a request whose form does not carry a CSRF token for action,
+// reporting whether it was refused.
+func Csrfrefused(w http.ResponseWriter, r *http.Request, action string) bool {
+	if err := Csrfcheck(r, action); err != nil {
+		log.Printf("Refused %s %s: %v\n", r.Method, r.URL.Path, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return true
+	}
+	return false
+}
+
+// Pages anyone may read without logging in.
+func openroute(path string) bool {
+	return (path == "/login/") || (path == "/intro/") || strings.HasPrefix(path, "/js/") || (path == apiprefix+"openapi.json")
+}
+
+// Wrap the server: give every browser a session, send the ones not logged
+// in to the login page, and refuse API calls that change something
+// unless they carry a token or a header a cross site form cannot send.
+func Guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api := strings.HasPrefix(r.URL.Path, apiprefix) || strings.HasSuffix(r.URL.Path, "/json/")
+		bearer := Bearer(r)
+
+		var s *session
+		if bearer {
+			s = &session{authed: true}
+		} else {
+			s = Session(w, r)
+		}
+		r = r.WithContext(context.WithValue(r.Context(), sessionkey{}, s))
+
+		if !s.authed && !openroute(r.URL.Path) {
+			if api {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				Apifail(w, http.StatusUnauthorized, ErrLogin)
+				return
+			}
+			http.Redirect(w, r, "/login/?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+
+		unsafe := (r.Method != http.MethodGet) && (r.Method != http.MethodHead)
+		if api && !bearer && (unsafe || strings.HasPrefix(r.URL.Path, "/setip/json/")) && (r.Header.Get("X-Requested-With") == "") {
+			Apifail(w, http.StatusForbidden, errors.New("send the X-Requested-With header or an Authorization bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Read a json page of this server, past Guard.
+func Selfget(u string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+selftoken)
+	return selfclient.Do(req)
+}
+
+// Accept the program websocket only from pages of this server.
+func Checkorigin(config *websocket.Config, req *http.Request) error {
+	o, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if (o == nil) || (o.Host != req.Host) {
+		return fmt.Errorf("websocket origin %v is not %s", o, req.Host)
+	}
+	config.Origin = o
+	return nil
+}
+
+// Web handler function for the login page.
+func loginhandler(w http.ResponseWriter, r *http.Request) {
+	var H Html
+	H.Version = version
+	H.Protocol = protocol
+	H.Update = update
+	H.Address = srvaddress
+	H.Port = srvport
+
+	r.ParseForm()
+	next := r.FormValue("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/intro/"
+	}
+
+	msg := ""
+	if r.Method == http.MethodPost {
+		if err := Csrfcheck(r, "/login/"); err != nil {
+			msg = err.Error()
+		} else if !Checkpassword(r.FormValue("password")) {
+			log.Printf("Login failed from %s\n", r.RemoteAddr)
+			time.Sleep(time.Second)
+			msg = "Wrong password"
+		} else {
+			// a new session, so an ID seen before the login is no use
+			sessmu.Lock()
+			delete(sessions, requestsession(r).id)
+			sessmu.Unlock()
+			Newsession(w, true)
+			log.Printf("Login from %s\n", r.RemoteAddr)
+			http.Redirect(w, r, next, http.StatusSeeOther)
+			return
+		}
+	}
+
+	Pagehead(w, H)
+	fmt.Fprintf(w, "<h2>Login</h2>\n")
+	if !Authrequired() {
+		fmt.Fprintf(w, "<p>No password is set, every browser is logged in.</p>\n")
+	}
+	if msg != "" {
+		fmt.Fprintf(w, "<p style=\"color:red\">%s</p>\n", template.HTMLEscapeString(msg))
+	}
+	fmt.Fprintf(w, "<form action=\"/login/\" method=\"post\">\n")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/login/"))
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"next\" value=\"%s\">\n", template.HTMLEscapeString(next))
+	fmt.Fprintf(w, " Password or token: <input type=\"password\" name=\"password\" autofocus>\n")
+	fmt.Fprintf(w, "<input class=\"btn\" type=\"submit\" value=\"Login\">")
+	fmt.Fprintf(w, "</form>")
+	fmt.Fprintf(w, "</body>\n")
+	fmt.Fprintf(w, "</html>\n")
+}
+
+// Web handler function to end the session.
+func logouthandler(w http.ResponseWriter, r *http.Request) {
+	sessmu.Lock()
+	delete(sessions, requestsession(r).id)
+	sessmu.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: sessioncookie, Value: "", Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/intro/", http.StatusSeeOther)
+}
@@ -0,0 +1,235 @@
+// Programming several boards at once from the web programmer
+// GPL2
+//
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Write the head, style and banner of a page.
+func Pagehead(w http.ResponseWriter, H Html) {
+	t, _ := template.New("head").Parse(w1p)
+	t.Execute(w, "head")
+
+	t1, _ := template.New("style").Parse(w1style)
+	t1.Execute(w, "style")
+
+	t2, _ := template.New("body").Parse(w2p)
+	t2.Execute(w, "body")
+
+	t3, _ := template.New("webbanner").Parse(banner)
+	t3.Execute(w, H)
+}
+
+// Discover the boards on the interface with the index.
+func Discoverindex(ctx context.Context, index int) (itr newopenhpsdr.Intface, str []newopenhpsdr.Hpsdrboard, er error) {
+	for _, intf := range newopenhpsdr.Interfaces() {
+		if intf.Index == index {
+			itr = intf
+		}
+	}
+	if itr.Ipv4 == "" {
+		return itr, str, fmt.Errorf("interface %d has no IPv4 address", index)
+	}
+	adr := itr.Ipv4 + ":" + newopenhpsdr.Boardport
+	bcadr := itr.Ipv4Bcast + ":" + newopenhpsdr.Boardport
+	str, er = newopenhpsdr.DiscoverContext(ctx, adr, bcadr, debug)
+	return itr, str, er
+}
+
+// Web handler function to select the boards and the RBF file of a batch.
+func batchhandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Served Batch Select page.")
+
+	var H Html
+	H.Version = version
+	H.Protocol = protocol
+	H.Update = update
+	H.Address = srvaddress
+	H.Port = srvport
+
+	r.ParseForm()
+	Pagehead(w, H)
+
+	index, _ := strconv.Atoi(r.FormValue("index"))
+	itr, str, err := Discoverindex(r.Context(), index)
+	if err != nil {
+		log.Println("Batch discovery ", err)
+	}
+
+	fmt.Fprintf(w, "<h2>Program several Radios</h2> <p> Select the radios to program with the same RBF file, up to %d are programmed at once.</p>\n", cap(jobslots))
+	fmt.Fprintf(w, "<p><b>Network interface:</b> %d: %s (%s)</p>\n", itr.Index, template.HTMLEscapeString(itr.Intname), itr.MAC)
+	if len(str) == 0 {
+		fmt.Fprintf(w, "<p><b>No radio answered.</b></p>\n")
+	}
+
+	fmt.Fprintf(w, "<form action=\"/batch/start/\" method=\"post\">\n")
+	fmt.Fprintf(w, "%s", Csrffield(r, "/batch/start/"))
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"index\" value=%d>\n", itr.Index)
+	fmt.Fprintf(w, "<table>\n")
+	for i := range str {
+		fmt.Fprintf(w, "<tr><td><input type=\"checkbox\" name=\"mac\" value=\"%s\" checked></td>", str[i].Macaddress)
+		fmt.Fprintf(w, "<td><b>%s</b></td><td>(%s)</td><td>(%s)</td><td>%s</td></tr>\n", str[i].Board, str[i].Macaddress, str[i].Baddress, str[i].Firmware)
+	}
+	fmt.Fprintf(w, "</table><br/>\n")
+	fmt.Fprintf(w, " RBF file: <select name=\"img\">\n")
+	for _, img := range Libraryimages() {
+		fmt.Fprintf(w, "<option value=\"%s\">%s (library %s)</option>\n", img.Sha256, template.HTMLEscapeString(img.Name), img.Sha256[:16])
+	}
+	for _, name := range Rbfdirfiles() {
+		fmt.Fprintf(w, "<option value=\"%s\">%s</option>\n", template.HTMLEscapeString(name), template.HTMLEscapeString(name))
+	}
+	fmt.Fprintf(w, "</select><br/>\n")
+	fmt.Fprintf(w, "<label><input type=\"checkbox\" name=\"verify\" checked> Verify the boards after programming</label><br/>\n")
+	fmt.Fprintf(w, "<label><input type=\"checkbox\" name=\"force\"> Program even when the RBF name or manifest does not match a board</label><br/>\n")
+	fmt.Fprintf(w, "<input class=\"btn\" type=\"submit\" value=\"Program\">")
+	fmt.Fprintf(w, "</form>")
+
+	fmt.Fprintf(w, "</body>\n")
+	fmt.Fprintf(w, "</html>\n")
+}
+
+// Web handler function creating one job per selected board and queuing
+// them, then sending the browser to the status page of the batch.
+func batchstarthandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Served Batch Start.")
+
+	r.ParseForm()
+	if Csrfrefused(w, r, "/batch/start/") {
+		return
+	}
+	index, _ := strconv.Atoi(r.FormValue("index"))
+	img := r.FormValue("img")
+	rbf, rerr := Resolveimage(img)
+	verify := r.FormValue("verify") == "on"
+	force := r.FormValue("force") == "on"
+
+	itr, str, err := Discoverindex(r.Context(), index)
+	if err != nil {
+		log.Println("Batch discovery ", err)
+	}
+
+	batch := Newid()
+	for _, mac := range r.Form["mac"] {
+		bd, err := newopenhpsdr.Findboard(str, mac)
+		if rerr != nil {
+			err = rerr
+		}
+		j := Newjob()
+		j.Update(func(st *Jobstate) {
+			st.Batch = batch
+			st.Index = itr.Index
+			st.Intface = itr.Intname
+			st.Board = bd
+			st.Board.Macaddress = mac
+			st.Rbffile = rbf
+			st.Verify = verify
+			st.Force = force
+		})
+		if (err == nil) && !force && !newopenhpsdr.Rbfforboard(bd, rbf) {
+			err = &newopenhpsdr.FileError{Filename: rbf, Err: fmt.Errorf("name does not match the board %s", bd.Board)}
+		}
+		if err != nil {
+			log.Printf("Batch %s: %s not started, %v\n", batch, mac, err)
+			j.Update(func(st *Jobstate) {
+				st.Frame = Progressframe{State: "checking", Erase: "Pending", Program: "Pending"}
+				st.Frame.fail(err)
+				st.State = st.Frame.State
+			})
+			continue
+		}
+		Startjob(j)
+	}
+	log.Printf("Batch %s: %d boards with %s\n", batch, len(r.Form["mac"]), img)
+
+	http.Redirect(w, r, "/batch/status/?batch="+batch, http.StatusSeeOther)
+}
+
+// Web handler function showing every board of a batch, reloaded until the
+// last one is finished.
+func batchstatushandler(w http.ResponseWriter, r *http.Request) {
+	var H Html
+	H.Version = version
+	H.Protocol = protocol
+	H.Update = update
+	H.Address = srvaddress
+	H.Port = srvport
+
+	r.ParseForm()
+	jl := Batchjobs(r.FormValue("batch"))
+
+	var done, failed, running int
+	for _, st := range jl {
+		switch st.State {
+		case "done":
+			done++
+		case "failed":
+			failed++
+		default:
+			running++
+		}
+	}
+
+	t, _ := template.New("head").Parse(w1p)
+	t.Execute(w, "head")
+	if running > 0 {
+		fmt.Fprintf(w, "<meta http-equiv=\"refresh\" content=\"2\">\n")
+	}
+	t1, _ := template.New("style").Parse(w1style)
+	t1.Execute(w, "style")
+	t2, _ := template.New("body").Parse(w2p)
+	t2.Execute(w, "body")
+	t3, _ := template.New("webbanner").Parse(banner)
+	t3.Execute(w, H)
+
+	fmt.Fprintf(w, "<h2>Batch %s</h2>\n", template.HTMLEscapeString(r.FormValue("batch")))
+	if running > 0 {
+		fmt.Fprintf(w, "<p><b>%d boards:</b> %d done, %d failed, %d running or queued</p>\n", len(jl), done, failed, running)
+	} else {
+		fmt.Fprintf(w, "<p><b>Finished, %d boards:</b> %d done, %d failed</p>\n", len(jl), done, failed)
+	}
+
+	fmt.Fprintf(w, "<table>\n")
+	fmt.Fprintf(w, "<tr><th>Board</th><th>MAC</th><th>Address</th><th>State</th><th>Progress</th><th>Retries</th><th>Seconds</th><th>Result</th></tr>\n")
+	for _, st := range jl {
+		fr := st.Frame
+		res := fr.Verify
+		color := "black"
+		if fr.State == "failed" {
+			res = fr.Error
+			color = "red"
+		}
+		fmt.Fprintf(w, "<tr style=\"color:%s\"><td>%s</td><td>%s</td><td>%s</td><td>%s</td>", color, st.Board.Board, st.Board.Macaddress, st.Board.Baddress, fr.State)
+		fmt.Fprintf(w, "<td><progress max=\"100\" value=\"%.0f\"></progress></td><td>%d</td><td>%.1f</td>", fr.Percent, fr.Retries, fr.Seconds)
+		fmt.Fprintf(w, "<td>%s</td></tr>\n", template.HTMLEscapeString(res))
+	}
+	fmt.Fprintf(w, "</table><br/>\n")
+
+	fmt.Fprintf(w, "<form method=\"link\" action=\"/nic/\" >")
+	fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"nic\" value=\"nic\"> Return</button>")
+	fmt.Fprintf(w, "</form>")
+
+	fmt.Fprintf(w, "</body>\n")
+	fmt.Fprintf(w, "</html>\n")
+}
+
+// Web handler function to produce the jobs json packet, of one batch when
+// the batch form value is given.
+func jobsjsonhandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	jl := Joblist()
+	if r.FormValue("batch") != "" {
+		jl = Batchjobs(r.FormValue("batch"))
+	}
+	enc := json.NewEncoder(w)
+	enc.Encode(jl)
+}
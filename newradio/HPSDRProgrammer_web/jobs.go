@@ -0,0 +1,258 @@
+// Programming jobs of the web programmer
+// GPL2
+//
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Jobs not touched for this long are dropped, unless they are flashing.
+const jobexpire time.Duration = 24 * time.Hour
+
+// Settings and state of one programming job.  Every browser session works
+// on its own job, the job ID is passed from page to page as the job form
+// value, so two tabs or two operators never share a board or an image.
+type Jobstate struct {
+	ID       string                  `json:"id"`
+	Index    int                     `json:"index"`             // interface the board was discovered on
+	Intface  string                  `json:"intface"`           // name of that interface
+	Targets  string                  `json:"targets,omitempty"` // board addresses to verify at, instead of broadcasting
+	Board    newopenhpsdr.Hpsdrboard `json:"board"`
+	Rbffile  string                  `json:"rbffile"`
+	Verify   bool                    `json:"verify"` // verify the board after programming
+	Force    bool                    `json:"force"`  // program even when the RBF manifest does not match
+	State    string                  `json:"state"`  // state of the last Progressframe
+	Batch    string                  `json:"batch,omitempty"`
+	Frame    Progressframe           `json:"progress"` // kept for jobs run by Startjob
+	Created  time.Time               `json:"created"`
+	Modified time.Time               `json:"modified"`
+}
+
+// A programming job, use State and Update to read and change it.
+type Job struct {
+	mu sync.Mutex
+	st Jobstate
+}
+
+var (
+	jobmu    sync.Mutex
+	jobs     = make(map[string]*Job)
+	flashing = make(map[string]string) // board MAC -> ID of the job programming it
+)
+
+// Jobs erasing or programming at once, the rest wait in Runjob.
+var jobslots = make(chan struct{}, newopenhpsdr.Batchparallel)
+
+// Errors of the job table
+var (
+	ErrNoJob     = errors.New("no such programming job")
+	ErrBoardBusy = errors.New("board is being programmed by another job")
+)
+
+// Copy of the job state.
+func (j *Job) State() Jobstate {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.st
+}
+
+// Change the job state under the job lock.
+func (j *Job) Update(f func(st *Jobstate)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	f(&j.st)
+	j.st.Modified = time.Now()
+}
+
+// Random ID for a job or a batch.
+func Newid() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// never expected, fall back on the clock
+		b = []byte(fmt.Sprintf("%016x", time.Now().UnixNano()))[:8]
+	}
+	return hex.EncodeToString(b)
+}
+
+// Create a job with a random ID and add it to the table.
+func Newjob() *Job {
+	now := time.Now()
+	j := &Job{st: Jobstate{ID: Newid(), State: "new", Created: now, Modified: now}}
+	j.st.Board = newopenhpsdr.ResetHpsdrboard(j.st.Board)
+
+	jobmu.Lock()
+	defer jobmu.Unlock()
+	expirejobs(now)
+	jobs[j.st.ID] = j
+	log.Printf("Job %s created\n", j.st.ID)
+	return j
+}
+
+// Look up a job by its ID.
+func Findjob(id string) (*Job, error) {
+	jobmu.Lock()
+	defer jobmu.Unlock()
+	j, ok := jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoJob, id)
+	}
+	return j, nil
+}
+
+// Job of the request, from the job form value.  A request without a known
+// job starts a new one.
+func Jobfor(r *http.Request) *Job {
+	if j, err := Findjob(r.FormValue("job")); err == nil {
+		return j
+	}
+	return Newjob()
+}
+
+// Every job in the table.
+func Joblist() (jl []Jobstate) {
+	jobmu.Lock()
+	all := make([]*Job, 0, len(jobs))
+	for _, j := range jobs {
+		all = append(all, j)
+	}
+	jobmu.Unlock()
+	for _, j := range all {
+		jl = append(jl, j.State())
+	}
+	return jl
+}
+
+// Reserve the board of the job for programming, a board is programmed by
+// one job at a time and none once the server is quitting.  Call
+// Releaseboard when the run ends.
+func Claimboard(id string, mac string) error {
+	jobmu.Lock()
+	defer jobmu.Unlock()
+	if Quitting() {
+		return ErrQuitting
+	}
+	if other, ok := flashing[mac]; ok {
+		return fmt.Errorf("%w: %s, job %s", ErrBoardBusy, mac, other)
+	}
+	flashing[mac] = id
+	return nil
+}
+
+// Free a board reserved with Claimboard.
+func Releaseboard(id string, mac string) {
+	jobmu.Lock()
+	defer jobmu.Unlock()
+	if flashing[mac] == id {
+		delete(flashing, mac)
+	}
+}
+
+// Run the job once a slot is free and its board is not programmed by
+// another job, see readsensor.  The job waits in the queued state.
+func Runjob(ctx context.Context, j *Job, obs newopenhpsdr.Observer, verify func()) (string, error) {
+	j.Update(func(st *Jobstate) { st.State = "queued" })
+	select {
+	case jobslots <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-jobslots }()
+
+	st := j.State()
+	if st.Board.Pcaddress == "" {
+		return "", fmt.Errorf("job %s: no board selected", st.ID)
+	}
+	if err := Claimboard(st.ID, st.Board.Macaddress); err != nil {
+		return "", err
+	}
+	defer Releaseboard(st.ID, st.Board.Macaddress)
+
+	j.Update(func(st *Jobstate) {
+		st.State = "checking"
+		if st.Frame.State == "queued" {
+			st.Frame.State = "checking"
+		}
+	})
+	log.Printf("Job %s programming %s (%s) with %s\n", st.ID, st.Board.Board, st.Board.Macaddress, st.Rbffile)
+	return readsensor(ctx, obs, verify, st)
+}
+
+// Queue the job and run it in the background, its progress is kept in
+// the job state for the batch status page.
+func Startjob(j *Job) {
+	j.Update(func(st *Jobstate) {
+		st.Frame = Progressframe{State: "queued", Erase: "Pending", Program: "Pending"}
+		st.State = "queued"
+	})
+	go func() {
+		start := time.Now()
+		obs := func(p newopenhpsdr.Progress) {
+			if p.Kind == newopenhpsdr.Blocksent {
+				return
+			}
+			j.Update(func(st *Jobstate) {
+				st.Frame.update(p)
+				st.Frame.Seconds = time.Since(start).Seconds()
+				st.State = st.Frame.State
+			})
+		}
+		verify := func() {
+			j.Update(func(st *Jobstate) {
+				st.Frame.State = "verifying"
+				st.State = "verifying"
+			})
+		}
+		v, err := Runjob(context.Background(), j, obs, verify)
+		j.Update(func(st *Jobstate) {
+			if err != nil {
+				if st.Frame.State == "queued" {
+					st.Frame.State = "checking"
+				}
+				st.Frame.fail(err)
+			} else {
+				st.Frame.State = "done"
+				st.Frame.Verify = v
+			}
+			st.Frame.Seconds = time.Since(start).Seconds()
+			st.State = st.Frame.State
+		})
+		log.Printf("Job %s %s\n", j.State().ID, j.State().State)
+	}()
+}
+
+// Jobs of a batch, in the order they were created.
+func Batchjobs(batch string) (jl []Jobstate) {
+	for _, st := range Joblist() {
+		if st.Batch == batch {
+			jl = append(jl, st)
+		}
+	}
+	sort.Slice(jl, func(i, k int) bool { return jl[i].Created.Before(jl[k].Created) })
+	return jl
+}
+
+// Drop the jobs not used for jobexpire, jobmu is held.
+func expirejobs(now time.Time) {
+	busy := make(map[string]bool)
+	for _, id := range flashing {
+		busy[id] = true
+	}
+	for id, j := range jobs {
+		if !busy[id] && now.Sub(j.State().Modified) > jobexpire {
+			delete(jobs, id)
+			log.Printf("Job %s expired\n", id)
+		}
+	}
+}
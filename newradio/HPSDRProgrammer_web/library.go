@@ -0,0 +1,336 @@
+// Firmware library of the web programmer, the uploaded RBF images
+// GPL2
+//
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Directory of the library in the RBF directory, and the file describing
+// each image.  An image is kept as library/<sha256>/<name>, so the same
+// file uploaded twice is stored once and the name still tells the board
+// and version.
+const (
+	librarydir  string = "library"
+	libraryinfo string = "image.json"
+)
+
+// Largest upload accepted, the largest flash of the known boards unless
+// -maxupload is given.
+var maxupload int64
+
+// A stored RBF image
+type Libraryimage struct {
+	Name     string                 `json:"name"` // file name as uploaded, cleaned
+	Path     string                 `json:"path"`
+	Sha256   string                 `json:"sha256"`
+	Size     int64                  `json:"size"`
+	Board    string                 `json:"board,omitempty"`    // from the name
+	Firmware string                 `json:"firmware,omitempty"` // from the name or the manifest
+	Uploaded time.Time              `json:"uploaded"`
+	Manifest *newopenhpsdr.Manifest `json:"manifest,omitempty"`
+}
+
+// Errors of the library
+var (
+	ErrNoImage    = errors.New("no such firmware image")
+	ErrImageInUse = errors.New("firmware image is being programmed")
+	ErrImageName  = errors.New("not a library SHA-256 or an RBF file name")
+)
+
+var sha256hex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+var unsafename = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func init() {
+	for _, b := range newopenhpsdr.Boardlist() {
+		if b.Flashsize > maxupload {
+			maxupload = b.Flashsize
+		}
+	}
+}
+
+// Directory of the library.
+func Librarypath() string {
+	return filepath.Join(rbffiledir, librarydir)
+}
+
+// File name of an upload without any directory, characters outside
+// letters, digits, dot, dash and underscore replaced.  It must end in .rbf.
+func Cleanname(name string) (string, error) {
+	// browsers on Windows send the full path with backslashes
+	name = name[strings.LastIndexAny(name, `/\`)+1:]
+	name = strings.Trim(unsafename.ReplaceAllString(name, "_"), "._")
+	if !strings.EqualFold(filepath.Ext(name), ".rbf") || (len(name) <= len(".rbf")) {
+		return "", &newopenhpsdr.FileError{Filename: name, Err: errors.New("want an .rbf file")}
+	}
+	return name, nil
+}
+
+// Store an uploaded image read from r in the library, checked before it
+// is kept.  An image already in the library is returned as it is.
+func Storeimage(r io.Reader, name string) (img Libraryimage, er error) {
+	name, err := Cleanname(name)
+	if err != nil {
+		return img, err
+	}
+	if err := os.MkdirAll(Librarypath(), 0755); err != nil {
+		return img, err
+	}
+
+	// write to a new file and hash it on the way, an earlier upload is
+	// never written over
+	tmp, err := os.CreateTemp(Librarypath(), "upload-*.rbf")
+	if err != nil {
+		return img, err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, maxupload+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return img, err
+	}
+	if n > maxupload {
+		return img, &newopenhpsdr.FileError{Filename: name, Err: fmt.Errorf("larger than %d bytes", maxupload)}
+	}
+
+	// the flash size of the board the name is for is checked as well
+	var brd newopenhpsdr.Hpsdrboard
+	if b, ok := newopenhpsdr.Rbfboard(name); ok {
+		brd.Board = b.Name
+	}
+	if _, err := newopenhpsdr.Validaterbf(tmp.Name(), brd); err != nil {
+		var fe *newopenhpsdr.FileError
+		if errors.As(err, &fe) {
+			fe.Filename = name
+		}
+		return img, err
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	dir := filepath.Join(Librarypath(), sum)
+	if img, err := Findimage(sum); err == nil {
+		log.Printf("Library: %s is already stored as %s\n", name, img.Path)
+		return img, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return img, err
+	}
+	img = Libraryimage{Name: name, Path: filepath.Join(dir, name), Sha256: sum, Size: n, Board: brd.Board, Uploaded: time.Now().UTC()}
+	img.Firmware = newopenhpsdr.Rbfexpect(name).Firmware
+	if err := os.Rename(tmp.Name(), img.Path); err != nil {
+		os.RemoveAll(dir)
+		return img, err
+	}
+	b, _ := json.MarshalIndent(img, "", "\t")
+	if err := os.WriteFile(filepath.Join(dir, libraryinfo), b, 0644); err != nil {
+		os.RemoveAll(dir)
+		return img, err
+	}
+	log.Printf("Library: stored %s %d bytes sha256 %s\n", name, n, sum)
+	return img, nil
+}
+
+// Read an image of the library by its SHA-256.
+func Findimage(sum string) (img Libraryimage, er error) {
+	if !sha256hex.MatchString(sum) {
+		return img, fmt.Errorf("%w: %q", ErrNoImage, sum)
+	}
+	dir := filepath.Join(Librarypath(), sum)
+	b, err := os.ReadFile(filepath.Join(dir, libraryinfo))
+	if err != nil {
+		return img, fmt.Errorf("%w: %s", ErrNoImage, sum)
+	}
+	if err := json.Unmarshal(b, &img); err != nil {
+		return img, &newopenhpsdr.FileError{Filename: filepath.Join(dir, libraryinfo), Err: err}
+	}
+	// the library may have moved with the RBF directory
+	img.Path = filepath.Join(dir, filepath.Base(img.Name))
+	img.Sha256 = sum
+	m, found, err := newopenhpsdr.Loadmanifest(img.Path)
+	if found && (err == nil) {
+		img.Manifest = &m
+		if m.Firmware != "" {
+			img.Firmware = m.Firmware
+		}
+	}
+	return img, nil
+}
+
+// Every image of the library, the newest first.
+func Libraryimages() (il []Libraryimage) {
+	dl, err := os.ReadDir(Librarypath())
+	if err != nil {
+		return il
+	}
+	for _, d := range dl {
+		if !d.IsDir() {
+			continue
+		}
+		if img, err := Findimage(d.Name()); err == nil {
+			il = append(il, img)
+		}
+	}
+	sort.Slice(il, func(i, k int) bool { return il[i].Uploaded.After(il[k].Uploaded) })
+	return il
+}
+
+// Remove an image from the library, unless a job is programming it.
+func Deleteimage(sum string) error {
+	img, err := Findimage(sum)
+	if err != nil {
+		return err
+	}
+	for _, st := range Busyjobs() {
+		if filepath.Clean(st.Rbffile) == img.Path {
+			return fmt.Errorf("%w: job %s", ErrImageInUse, st.ID)
+		}
+	}
+	log.Printf("Library: deleted %s sha256 %s\n", img.Name, sum)
+	return os.RemoveAll(filepath.Dir(img.Path))
+}
+
+// Path of the RBF file a job asks for: the SHA-256 of a library image, a
+// file in the RBF directory, or else the newest library image with that
+// name.  Paths are refused, so nothing outside the two can be programmed.
+func Resolveimage(rbf string) (string, error) {
+	if sha256hex.MatchString(rbf) {
+		img, err := Findimage(rbf)
+		return img.Path, err
+	}
+	if (rbf == "") || (rbf == ".") || (rbf == "..") || filepath.IsAbs(rbf) || strings.ContainsAny(rbf, `/\`) || (rbf != filepath.Base(rbf)) {
+		return "", &newopenhpsdr.FileError{Filename: rbf, Err: ErrImageName}
+	}
+	p := filepath.Join(rbffiledir, rbf)
+	if fi, err := os.Stat(p); (err == nil) && fi.Mode().IsRegular() {
+		return p, nil
+	}
+	for _, img := range Libraryimages() {
+		if img.Name == rbf {
+			return img.Path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoImage, rbf)
+}
+
+// Names of the RBF files in the RBF directory, as Resolveimage takes them.
+func Rbfdirfiles() (names []string) {
+	dl, err := os.ReadDir(rbffiledir)
+	if err != nil {
+		return names
+	}
+	for _, d := range dl {
+		if d.Type().IsRegular() && strings.EqualFold(filepath.Ext(d.Name()), ".rbf") {
+			names = append(names, d.Name())
+		}
+	}
+	return names
+}
+
+// Web handler function listing the library.  With a job whose board is
+// selected, the images for that board can be programmed from here.
+func libraryhandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Served Firmware Library page.")
+
+	var H Html
+	H.Version = version
+	H.Protocol = protocol
+	H.Update = update
+	H.Address = srvaddress
+	H.Port = srvport
+
+	r.ParseForm()
+	Pagehead(w, H)
+
+	var st Jobstate
+	if j, err := Findjob(r.FormValue("job")); err == nil {
+		st = j.State()
+	}
+	selected := st.Board.Pcaddress != ""
+
+	il := Libraryimages()
+	fmt.Fprintf(w, "<h2>Firmware Library</h2> <p> %d RBF images in %s, up to %d bytes each.</p>\n", len(il), template.HTMLEscapeString(Librarypath()), maxupload)
+	if selected {
+		fmt.Fprintf(w, "<p><b>Selected board:</b> %s (%s) (%s)</p>\n", st.Board.Board, st.Board.Macaddress, st.Board.Baddress)
+	}
+
+	fmt.Fprintf(w, "<table>\n")
+	fmt.Fprintf(w, "<tr><th>Board</th><th>Firmware</th><th>File</th><th>SHA-256</th><th>Size</th><th>Uploaded</th><th></th><th></th></tr>\n")
+	for _, img := range il {
+		fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td><td>%s</td>", img.Board, template.HTMLEscapeString(img.Firmware), template.HTMLEscapeString(img.Name))
+		fmt.Fprintf(w, "<td title=\"%s\"><tt>%s</tt></td><td>%d</td><td>%s</td>", img.Sha256, img.Sha256[:16], img.Size, img.Uploaded.Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(w, "<td>")
+		if selected && newopenhpsdr.Rbfforboard(st.Board, img.Path) {
+			fmt.Fprintf(w, "<form action=\"/file/\">")
+			fmt.Fprintf(w, "%s", Csrffield(r, "/file/"))
+			fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", st.ID)
+			fmt.Fprintf(w, "<input type=\"hidden\" name=\"img\" value=\"%s\">\n", img.Sha256)
+			fmt.Fprintf(w, "<input type=\"hidden\" name=\"verify\" value=\"on\">\n")
+			fmt.Fprintf(w, "<input class=\"btn\" type=\"submit\" value=\"Program\">")
+			fmt.Fprintf(w, "</form>")
+		}
+		fmt.Fprintf(w, "</td><td>")
+		fmt.Fprintf(w, "<form action=\"/library/delete/\" method=\"post\">")
+		fmt.Fprintf(w, "%s", Csrffield(r, "/library/delete/"))
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", st.ID)
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"sha256\" value=%s>\n", img.Sha256)
+		fmt.Fprintf(w, "<input class=\"btn\" type=\"submit\" value=\"Delete\">")
+		fmt.Fprintf(w, "</form>")
+		fmt.Fprintf(w, "</td></tr>\n")
+	}
+	fmt.Fprintf(w, "</table><br/>\n")
+
+	fmt.Fprintf(w, "<form method=\"link\" action=\"/nic/\" >")
+	fmt.Fprintf(w, "<input type=\"hidden\" name=\"job\" value=%s>\n", st.ID)
+	fmt.Fprintf(w, "<button class=\"btn\" type=\"submit\" name=\"nic\" value=\"nic\"> Return</button>")
+	fmt.Fprintf(w, "</form>")
+
+	fmt.Fprintf(w, "</body>\n")
+	fmt.Fprintf(w, "</html>\n")
+}
+
+// Web handler function deleting a library image, then showing the library
+// again.
+func librarydeletehandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	if Csrfrefused(w, r, "/library/delete/") {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := Deleteimage(r.FormValue("sha256")); err != nil {
+		log.Println("Library delete ", err)
+		var H Html
+		H.Version = version
+		H.Protocol = protocol
+		H.Update = update
+		H.Address = srvaddress
+		H.Port = srvport
+		Pagehead(w, H)
+		Errorpage(w, err)
+		return
+	}
+	http.Redirect(w, r, "/library/?job="+url.QueryEscape(r.FormValue("job")), http.StatusSeeOther)
+}
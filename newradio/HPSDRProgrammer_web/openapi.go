@@ -0,0 +1,329 @@
+// OpenAPI description of the REST API
+// GPL2
+//
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// OpenAPI 3.0 document of the routes in api.go, served at
+// /api/v1/openapi.json.  Keep it in step with apihandler and with the
+// client in the hpsdrclient package.
+const openapi string = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "HPSDRProgrammer web API",
+    "version": "{{version}}",
+    "description": "Discover, re-address and program openHPSDR protocol 2 boards. When the server runs with -password or -token, calls need the token as a bearer token, or the session cookie of a browser that logged in. Calls other than GET need the bearer token or an X-Requested-With header."
+  },
+  "servers": [{"url": "/api/v1"}],
+  "security": [{"bearer": []}, {}],
+  "paths": {
+    "/interfaces": {
+      "get": {
+        "summary": "Network interfaces of the server",
+        "operationId": "interfaces",
+        "responses": {
+          "200": {"description": "Interfaces", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Intface"}}}}}
+        }
+      }
+    },
+    "/discoveries": {
+      "get": {
+        "summary": "Recent discoveries, oldest first",
+        "operationId": "discoveries",
+        "responses": {
+          "200": {"description": "Discoveries", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Discovery"}}}}}
+        }
+      },
+      "post": {
+        "summary": "Discover the boards on an interface or at addresses",
+        "operationId": "discover",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Discoveryrequest"}}}},
+        "responses": {
+          "201": {"description": "Discovery", "headers": {"Location": {"schema": {"type": "string"}}}, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Discovery"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/discoveries/{id}": {
+      "get": {
+        "summary": "One discovery",
+        "operationId": "discovery",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "Discovery", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Discovery"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/boards/{mac}/ip": {
+      "put": {
+        "summary": "Set the IP address of a board, replies once the board answers at the new address",
+        "operationId": "setip",
+        "parameters": [{"name": "mac", "in": "path", "required": true, "schema": {"type": "string"}, "example": "0:1c:c0:a2:13:1"}],
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Setiprequest"}}}},
+        "responses": {
+          "200": {"description": "Address changed", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SetIPmessage"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "504": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/firmware": {
+      "get": {
+        "summary": "Images of the firmware library, newest first",
+        "operationId": "library",
+        "responses": {
+          "200": {"description": "Images", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Firmware"}}}}}
+        }
+      },
+      "post": {
+        "summary": "Upload an RBF file to the firmware library, stored by its SHA-256",
+        "operationId": "upload",
+        "requestBody": {"required": true, "content": {"multipart/form-data": {"schema": {"type": "object", "properties": {"file": {"type": "string", "format": "binary"}}, "required": ["file"]}}}},
+        "responses": {
+          "201": {"description": "Stored and checked, or already in the library", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Firmware"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/firmware/{sha256}": {
+      "parameters": [{"name": "sha256", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[0-9a-f]{64}$"}}],
+      "get": {
+        "summary": "One image of the firmware library",
+        "operationId": "image",
+        "responses": {
+          "200": {"description": "Image", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Firmware"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "delete": {
+        "summary": "Remove an image from the firmware library",
+        "operationId": "deleteimage",
+        "responses": {
+          "204": {"description": "Removed"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/jobs": {
+      "get": {
+        "summary": "Every programming job, oldest first",
+        "operationId": "jobs",
+        "responses": {
+          "200": {"description": "Jobs", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Job"}}}}}
+        }
+      },
+      "post": {
+        "summary": "Erase and program a board, the job runs in the background",
+        "operationId": "program",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Jobrequest"}}}},
+        "responses": {
+          "202": {"description": "Job queued", "headers": {"Location": {"schema": {"type": "string"}}}, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "503": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/jobs/{id}": {
+      "get": {
+        "summary": "Status of one job",
+        "operationId": "job",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "Job", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "bearer": {"type": "http", "scheme": "bearer", "description": "The -token of the server"}
+    },
+    "responses": {
+      "Error": {"description": "Failed call", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    },
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "properties": {
+          "status": {"type": "integer"},
+          "code": {"type": "string", "enum": ["badrequest", "unauthorized", "forbidden", "notfound", "timeout", "conflict", "file", "method", "unavailable", "failed"]},
+          "error": {"type": "string"}
+        }
+      },
+      "Ipv4addr": {
+        "type": "object",
+        "properties": {
+          "ipv4": {"type": "string"},
+          "netmask": {"type": "string"},
+          "network": {"type": "string"},
+          "ipv4bcast": {"type": "string"}
+        }
+      },
+      "Intface": {
+        "type": "object",
+        "properties": {
+          "index": {"type": "integer"},
+          "intname": {"type": "string"},
+          "mac": {"type": "string"},
+          "ipv4": {"type": "string"},
+          "ipv4bcast": {"type": "string"},
+          "ipv6": {"type": "string"},
+          "ipv4addrs": {"type": "array", "items": {"$ref": "#/components/schemas/Ipv4addr"}}
+        }
+      },
+      "Board": {
+        "type": "object",
+        "properties": {
+          "status": {"type": "string"},
+          "board": {"type": "string", "example": "HERMES"},
+          "boardid": {"type": "integer"},
+          "baddress": {"type": "string", "example": "192.168.1.20:1024"},
+          "pcaddress": {"type": "string"},
+          "firmware": {"type": "string", "example": "10.3"},
+          "protocol": {"type": "string"},
+          "receivers": {"type": "integer"},
+          "macaddress": {"type": "string", "example": "0:1c:c0:a2:13:1"}
+        }
+      },
+      "Discoveryrequest": {
+        "type": "object",
+        "properties": {
+          "index": {"type": "integer", "description": "Interface to broadcast on"},
+          "targets": {"type": "string", "description": "Comma separated board addresses to unicast to instead"}
+        }
+      },
+      "Discovery": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "index": {"type": "integer"},
+          "intface": {"type": "string"},
+          "targets": {"type": "string"},
+          "time": {"type": "string", "format": "date-time"},
+          "boards": {"type": "array", "items": {"$ref": "#/components/schemas/Board"}}
+        }
+      },
+      "Setiprequest": {
+        "type": "object",
+        "required": ["address"],
+        "properties": {
+          "index": {"type": "integer"},
+          "targets": {"type": "string"},
+          "address": {"type": "string", "description": "New IPv4 address, or dhcp (255.255.255.255); multicast, loopback, network and broadcast addresses are refused"}
+        }
+      },
+      "SetIPmessage": {
+        "type": "object",
+        "properties": {
+          "oldadress": {"type": "string"},
+          "newadress": {"type": "string"},
+          "macaddress": {"type": "string"},
+          "message": {"type": "string"},
+          "warning": {"type": "string", "description": "Set when the address is outside the subnet of the interface"},
+          "board": {"$ref": "#/components/schemas/Board"}
+        }
+      },
+      "Rbfinfo": {
+        "type": "object",
+        "properties": {
+          "filename": {"type": "string"},
+          "size": {"type": "integer"},
+          "blocks": {"type": "integer"},
+          "preamble": {"type": "integer"},
+          "flashsize": {"type": "integer"}
+        }
+      },
+      "Manifest": {
+        "type": "object",
+        "properties": {
+          "file": {"type": "string"},
+          "board": {"type": "string"},
+          "firmware": {"type": "string"},
+          "sha256": {"type": "string"},
+          "notes": {"type": "string"},
+          "path": {"type": "string"}
+        }
+      },
+      "Firmware": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "path": {"type": "string"},
+          "sha256": {"type": "string", "description": "Use as the rbf of a Jobrequest"},
+          "rbf": {"$ref": "#/components/schemas/Rbfinfo"},
+          "board": {"type": "string"},
+          "firmware": {"type": "string"},
+          "uploaded": {"type": "string", "format": "date-time"},
+          "manifest": {"$ref": "#/components/schemas/Manifest"}
+        }
+      },
+      "Jobrequest": {
+        "type": "object",
+        "required": ["mac", "rbf"],
+        "properties": {
+          "index": {"type": "integer"},
+          "targets": {"type": "string"},
+          "mac": {"type": "string"},
+          "rbf": {"type": "string", "description": "SHA-256 or name of a library image, or the name of a file in the RBF directory; paths are refused"},
+          "verify": {"type": "boolean"},
+          "force": {"type": "boolean"}
+        }
+      },
+      "Progress": {
+        "type": "object",
+        "properties": {
+          "state": {"type": "string", "enum": ["queued", "checking", "erasing", "erased", "programming", "programmed", "verifying", "done", "failed"]},
+          "erase": {"type": "string"},
+          "program": {"type": "string"},
+          "verify": {"type": "string"},
+          "percent": {"type": "number"},
+          "block": {"type": "integer"},
+          "blocks": {"type": "integer"},
+          "retries": {"type": "integer"},
+          "rate": {"type": "number", "description": "Bytes per second"},
+          "eta": {"type": "number", "description": "Seconds left"},
+          "seconds": {"type": "number"},
+          "error": {"type": "string"}
+        }
+      },
+      "Job": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "index": {"type": "integer"},
+          "intface": {"type": "string"},
+          "targets": {"type": "string"},
+          "board": {"$ref": "#/components/schemas/Board"},
+          "rbffile": {"type": "string"},
+          "verify": {"type": "boolean"},
+          "force": {"type": "boolean"},
+          "state": {"type": "string"},
+          "batch": {"type": "string"},
+          "progress": {"$ref": "#/components/schemas/Progress"},
+          "created": {"type": "string", "format": "date-time"},
+          "modified": {"type": "string", "format": "date-time"}
+        }
+      }
+    }
+  }
+}
+`
+
+// Serve the OpenAPI document.
+func openapihandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		Apimethod(w, r, http.MethodGet)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(strings.Replace(openapi, "{{version}}", version, 1)))
+}
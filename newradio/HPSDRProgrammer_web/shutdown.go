@@ -0,0 +1,126 @@
+// Shutting the web programmer down without cutting a flash short
+// GPL2
+//
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Time the open requests get to finish once the server shuts down.
+const shutdowntimeout time.Duration = 10 * time.Second
+
+// A second signal within this time quits even while boards are flashing.
+const signalwindow time.Duration = 5 * time.Second
+
+var (
+	quitmu   sync.Mutex
+	quitting bool
+	quitnow  = make(chan string, 1)
+)
+
+// Runs started once the server is quitting fail with this error.
+var ErrQuitting = errors.New("the programmer is shutting down")
+
+// Report whether a quit is pending or under way.
+func Quitting() bool {
+	quitmu.Lock()
+	defer quitmu.Unlock()
+	return quitting
+}
+
+// Jobs whose board is claimed, from the check of the file through the
+// erase, program and verify.  The server must not stop while there are.
+func Busyjobs() (jl []Jobstate) {
+	jobmu.Lock()
+	var busy []*Job
+	for _, id := range flashing {
+		if j, ok := jobs[id]; ok {
+			busy = append(busy, j)
+		}
+	}
+	jobmu.Unlock()
+	for _, j := range busy {
+		jl = append(jl, j.State())
+	}
+	return jl
+}
+
+// Ask the server to quit.  With jobs busy the quit is refused and they
+// are returned, unless wait is set: then no new run starts and the server
+// quits when the last busy job ends.
+func Requestquit(reason string, wait bool) []Jobstate {
+	busy := Busyjobs()
+	if (len(busy) > 0) && !wait {
+		log.Printf("Quit refused, %d boards are being programmed\n", len(busy))
+		return busy
+	}
+
+	quitmu.Lock()
+	defer quitmu.Unlock()
+	if quitting {
+		return busy
+	}
+	quitting = true
+	if len(busy) > 0 {
+		log.Printf("Quitting when the %d boards being programmed are done\n", len(busy))
+	}
+	go func() {
+		for len(Busyjobs()) > 0 {
+			time.Sleep(500 * time.Millisecond)
+		}
+		quitnow <- reason
+	}()
+	return busy
+}
+
+// Serve until a quit request or SIGINT/SIGTERM, then shut the server down
+// gracefully.  A signal while boards are flashing defers the quit until
+// they are done, a second one within signalwindow quits at once.
+func Serve(srv *http.Server) {
+	errc := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errc <- srv.ListenAndServeTLS("", "")
+		} else {
+			errc <- srv.ListenAndServe()
+		}
+	}()
+
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	var warned time.Time
+	var reason string
+	for reason == "" {
+		select {
+		case err := <-errc:
+			log.Fatal(err)
+		case s := <-sig:
+			if !warned.IsZero() && (time.Since(warned) < signalwindow) {
+				log.Fatalf("%v again: quitting with %d boards being programmed, they are left with a partial flash!", s, len(Busyjobs()))
+			}
+			warned = time.Now()
+			if busy := Requestquit(s.String(), true); len(busy) > 0 {
+				log.Printf("%v: %d boards are being programmed, quitting when they are done.  Send it again within %v to quit anyway.\n", s, len(busy), signalwindow)
+			}
+		case reason = <-quitnow:
+		}
+	}
+
+	log.Printf("Shutting down (%s) ...\n", reason)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdowntimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown: %v\n", err)
+	}
+	log.Println("Program shut down by user!")
+}
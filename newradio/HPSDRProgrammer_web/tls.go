@@ -0,0 +1,200 @@
+// TLS of the web programmer, with a self-signed certificate when none is given
+// GPL2
+//
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Names of the generated certificate and key in the RBF directory
+const (
+	selfcertname string        = "HPSDRProgrammer_web.crt"
+	selfkeyname  string        = "HPSDRProgrammer_web.key"
+	selfcertlife time.Duration = 2 * 365 * 24 * time.Hour
+)
+
+// Scheme of the server, https with -tls.
+var srvscheme string = "http"
+
+// Certificate the server answers with, Selfget trusts only it.
+var srvcert *tls.Certificate
+
+// Client of Selfget, pinned to srvcert with -tls.
+var selfclient *http.Client = http.DefaultClient
+
+// Names and addresses the generated certificate is for: this computer,
+// the server address and the IPv4 address of every interface.
+func Selfhosts() (hosts []string) {
+	hosts = append(hosts, "localhost", "127.0.0.1", "::1")
+	if hn, err := os.Hostname(); err == nil {
+		hosts = append(hosts, hn, hn+".local")
+	}
+	if ip := net.ParseIP(srvaddress); (ip == nil) || !ip.IsUnspecified() {
+		hosts = append(hosts, srvaddress)
+	}
+	for _, intf := range newopenhpsdr.Interfaces() {
+		if intf.Ipv4 != "" {
+			hosts = append(hosts, intf.Ipv4)
+		}
+	}
+	return hosts
+}
+
+// Write a self-signed ECDSA certificate for the hosts and its key.
+func Selfsigned(certfile string, keyfile string, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	tmpl := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"OpenHPSDR"}, CommonName: "HPSDRProgrammer_web"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfcertlife),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	seen := make(map[string]bool)
+	for _, h := range hosts {
+		if (h == "") || seen[h] {
+			continue
+		}
+		seen[h] = true
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	kb, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(certfile), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyfile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certfile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// Report why a generated certificate has to be made again, empty when it
+// is still good for the server address.
+func selfstale(leaf *x509.Certificate) string {
+	if time.Now().Add(7 * 24 * time.Hour).After(leaf.NotAfter) {
+		return "expires " + leaf.NotAfter.Format("2006-01-02")
+	}
+	if ip := net.ParseIP(srvaddress); (ip == nil) || !ip.IsUnspecified() {
+		if err := leaf.VerifyHostname(srvaddress); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// SHA-256 fingerprint of a certificate as browsers show it.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	hx := make([]string, len(sum))
+	for i, b := range sum {
+		hx[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(hx, ":")
+}
+
+// Load the TLS certificate and key of the server.  Without file names the
+// self-signed certificate in the RBF directory is used, made when it is
+// missing, expiring or not for the server address.
+func Loadtls(certfile string, keyfile string) (*tls.Config, error) {
+	if (certfile == "") != (keyfile == "") {
+		return nil, errors.New("give both -cert and -key, or neither for a self-signed certificate")
+	}
+
+	generated := certfile == ""
+	if generated {
+		certfile = filepath.Join(rbffiledir, selfcertname)
+		keyfile = filepath.Join(rbffiledir, selfkeyname)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certfile, keyfile)
+	if err == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	}
+	if generated {
+		why := ""
+		if err != nil {
+			why = err.Error()
+		} else {
+			why = selfstale(cert.Leaf)
+		}
+		if why != "" {
+			log.Printf("Making a self-signed certificate %s (%s)\n", certfile, why)
+			if err = Selfsigned(certfile, keyfile, Selfhosts()); err != nil {
+				return nil, err
+			}
+			cert, err = tls.LoadX509KeyPair(certfile, keyfile)
+			if err == nil {
+				cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("TLS certificate %s: %v", certfile, err)
+	}
+
+	log.Printf("TLS certificate %s for %v %v, valid until %s\n", certfile, cert.Leaf.DNSNames, cert.Leaf.IPAddresses, cert.Leaf.NotAfter.Format("2006-01-02"))
+	log.Printf("TLS certificate SHA-256 fingerprint %s\n", Fingerprint(cert.Certificate[0]))
+
+	srvscheme = "https"
+	srvcert = &cert
+
+	// the server reads its own json pages at srvaddress, which need not be
+	// a name in the certificate, so trust the certificate itself instead
+	selfclient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+			if (len(raw) == 0) || !bytes.Equal(raw[0], srvcert.Certificate[0]) {
+				return errors.New("not the certificate of this server")
+			}
+			return nil
+		},
+	}}}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
@@ -0,0 +1,63 @@
+// Program to simulate an HPSDR board for the programmers
+// new protocol version
+//
+// GPL2
+//
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/simhpsdr"
+)
+
+func main() {
+	address := flag.String("address", "127.0.0.1:1024", "Address and port the simulated board listens on")
+	board := flag.Int("board", 1, "Board ID reported by discovery")
+	mac := flag.String("mac", "00:1c:c0:a2:13:01", "Board MAC address")
+	fw := flag.Int("firmware", 103, "Firmware version reported by discovery (103 = 10.3)")
+	ed := flag.Int("edelay", 2, "Seconds the erase takes")
+	drop := flag.Float64("drop", 0, "Fraction of replies to drop")
+	dup := flag.Float64("dup", 0, "Fraction of replies to duplicate")
+	reorder := flag.Float64("reorder", 0, "Fraction of replies to send out of order")
+	seed := flag.Int64("seed", 1, "Random seed for the faults")
+	debug := flag.Bool("debug", false, "Log every packet received")
+
+	flag.Parse()
+
+	hw, err := net.ParseMAC(*mac)
+	if err != nil {
+		log.Fatalf("Bad MAC address %s: %v\n", *mac, err)
+	}
+
+	cfg := simhpsdr.Config{
+		BoardID:   byte(*board),
+		MAC:       hw,
+		Firmware:  byte(*fw),
+		Receivers: 4,
+		Erasetime: time.Duration(*ed) * time.Second,
+		Debug:     *debug,
+	}
+	if *drop > 0 || *dup > 0 || *reorder > 0 {
+		cfg.Fault = simhpsdr.Randomfaults(*seed, *drop, *dup, *reorder)
+	}
+
+	b, err := simhpsdr.New(*address, cfg)
+	if err != nil {
+		log.Fatalf("Could not start the board: %v\n", err)
+	}
+	log.Printf("Simulated board %d (%s) listening on %v\n", *board, hw, b.Addr())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	<-sig
+
+	st := b.Stats()
+	log.Printf("Received discover %d, setip %d, erase %d, program %d\n", st.Discover, st.Setip, st.Erase, st.Program)
+	b.Close()
+}
@@ -0,0 +1,348 @@
+// Package to simulate an openHPSDR Radio Board
+// answering the new protocol programming packets
+// GPL2
+//
+package simhpsdr
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/TAPR/OpenHPSDR-Protocol2-Programmers/newopenhpsdr"
+)
+
+// Fault is what happens to a reply before the board sends it.
+type Fault int
+
+const (
+	Deliver   Fault = iota // send the reply
+	Drop                   // never send the reply
+	Duplicate              // send the reply twice
+	Reorder                // hold the reply and send it after the next one
+)
+
+// Faultfunc decides the fate of the reply to a packet of type cmd with
+// sequence number seq.
+type Faultfunc func(cmd byte, seq uint32) Fault
+
+// Config describes the simulated board.
+type Config struct {
+	BoardID   byte
+	MAC       net.HardwareAddr
+	Protocol  byte
+	Firmware  byte
+	Receivers byte
+	Erasetime time.Duration
+	Fault     Faultfunc
+	Debug     bool
+
+	// After the last program block the board is silent for Reboottime
+	// and then reports Newfirmware, when it is set.
+	Reboottime  time.Duration
+	Newfirmware byte
+
+	// Address a DHCP Set IP packet moves the board to, it stays where it
+	// is when nil.
+	Dhcpip net.IP
+}
+
+// Stats counts the packets the board has received, by command byte.
+type Stats struct {
+	Discover int
+	Setip    int
+	Erase    int
+	Program  int
+}
+
+// Board is a simulated board listening on a UDP port.
+type Board struct {
+	cfg Config
+
+	mu       sync.Mutex
+	conn     *net.UDPConn
+	ip       net.IP
+	firmware byte
+	image    []byte
+	blocks   uint32
+	erasing  bool
+	rebooted time.Time
+	held     []held
+	stats    Stats
+	closed   bool
+	done     chan struct{}
+}
+
+type held struct {
+	buf []byte
+	to  *net.UDPAddr
+}
+
+// Default board, New uses its MAC and Protocol when a Config leaves them empty.
+var Defaultconfig = Config{
+	BoardID:   1,
+	MAC:       net.HardwareAddr{0x00, 0x1c, 0xc0, 0xa2, 0x13, 0x01},
+	Protocol:  18,
+	Firmware:  103,
+	Receivers: 4,
+	Erasetime: 500 * time.Millisecond,
+}
+
+// Create a board listening on addrStr, such as "127.0.0.1:1024" or
+// "127.0.0.1:0" for any free port, and start answering packets.
+func New(addrStr string, cfg Config) (*Board, error) {
+	if cfg.MAC == nil {
+		cfg.MAC = Defaultconfig.MAC
+	}
+	if len(cfg.MAC) != 6 {
+		return nil, errors.New("simhpsdr: MAC must be 6 bytes")
+	}
+	if cfg.Protocol == 0 {
+		cfg.Protocol = Defaultconfig.Protocol
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", addrStr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Board{cfg: cfg, conn: conn, firmware: cfg.Firmware, done: make(chan struct{})}
+	b.ip = conn.LocalAddr().(*net.UDPAddr).IP
+	go b.serve(conn)
+	return b, nil
+}
+
+// Random faults with the given probabilities, repeatable for a seed.
+func Randomfaults(seed int64, drop, dup, reorder float64) Faultfunc {
+	var mu sync.Mutex
+	r := rand.New(rand.NewSource(seed))
+	return func(cmd byte, seq uint32) Fault {
+		mu.Lock()
+		defer mu.Unlock()
+		x := r.Float64()
+		switch {
+		case x < drop:
+			return Drop
+		case x < drop+dup:
+			return Duplicate
+		case x < drop+dup+reorder:
+			return Reorder
+		}
+		return Deliver
+	}
+}
+
+// Address the board is listening on.
+func (b *Board) Addr() *net.UDPAddr {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Image written by the Program packets so far.
+func (b *Board) Image() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.image...)
+}
+
+// Stats of the packets received so far.
+func (b *Board) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// Stop the board and release its port.
+func (b *Board) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	conn := b.conn
+	b.mu.Unlock()
+	err := conn.Close()
+	<-b.done
+	return err
+}
+
+func (b *Board) serve(conn *net.UDPConn) {
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			b.mu.Lock()
+			moved := b.conn != conn
+			b.mu.Unlock()
+			if !moved {
+				close(b.done)
+			}
+			return
+		}
+		if n < 5 {
+			continue
+		}
+		pkt := append([]byte(nil), buf[:n]...)
+		if b.cfg.Debug {
+			log.Printf("simhpsdr: %d bytes from %v type %d", n, from, pkt[4])
+		}
+		b.handle(pkt, from)
+	}
+}
+
+func (b *Board) handle(pkt []byte, from *net.UDPAddr) {
+	switch pkt[4] {
+	case newopenhpsdr.Cmddiscover:
+		var p newopenhpsdr.DiscoveryRequest
+		if p.UnmarshalBinary(pkt) != nil {
+			return
+		}
+		b.mu.Lock()
+		b.stats.Discover++
+		booting := time.Now().Before(b.rebooted)
+		fw := b.firmware
+		b.mu.Unlock()
+		if booting {
+			return
+		}
+		r := newopenhpsdr.DiscoveryReply{
+			Sequence:  p.Sequence,
+			MAC:       b.cfg.MAC,
+			BoardID:   b.cfg.BoardID,
+			Protocol:  b.cfg.Protocol,
+			Firmware:  fw,
+			Receivers: b.cfg.Receivers,
+		}
+		rb, _ := r.MarshalBinary()
+		b.reply(p.Sequence, pkt[4], rb, from)
+
+	case newopenhpsdr.Cmdsetip:
+		var p newopenhpsdr.SetIPRequest
+		if p.UnmarshalBinary(pkt) != nil || p.MAC.String() != b.cfg.MAC.String() {
+			return
+		}
+		b.mu.Lock()
+		b.stats.Setip++
+		b.mu.Unlock()
+		b.move(p.IP)
+
+	case newopenhpsdr.Cmderase:
+		var p newopenhpsdr.EraseRequest
+		if p.UnmarshalBinary(pkt) != nil {
+			return
+		}
+		b.mu.Lock()
+		b.stats.Erase++
+		b.erasing = true
+		b.image = nil
+		b.blocks = 0
+		b.mu.Unlock()
+		ack, _ := newopenhpsdr.EraseAck{Sequence: p.Sequence, MAC: b.cfg.MAC}.MarshalBinary()
+		b.reply(p.Sequence, pkt[4], ack, from)
+		go func() {
+			time.Sleep(b.cfg.Erasetime)
+			b.mu.Lock()
+			b.erasing = false
+			b.mu.Unlock()
+			b.reply(p.Sequence, pkt[4], ack, from)
+		}()
+
+	case newopenhpsdr.Cmdprogram:
+		var p newopenhpsdr.ProgramData
+		if p.UnmarshalBinary(pkt) != nil {
+			return
+		}
+		b.mu.Lock()
+		b.stats.Program++
+		if b.erasing {
+			// flash is busy, the firmware ignores the block
+			b.mu.Unlock()
+			return
+		}
+		b.blocks = p.Blocks
+		end := int(p.Sequence+1) * newopenhpsdr.Blocklen
+		if len(b.image) < end {
+			b.image = append(b.image, make([]byte, end-len(b.image))...)
+		}
+		copy(b.image[end-newopenhpsdr.Blocklen:end], p.Data[:])
+		if p.Sequence+1 == p.Blocks {
+			// last block, the board reboots into the new image
+			b.rebooted = time.Now().Add(b.cfg.Reboottime)
+			if b.cfg.Newfirmware != 0 {
+				b.firmware = b.cfg.Newfirmware
+			}
+		}
+		b.mu.Unlock()
+		ack, _ := newopenhpsdr.ProgramAck{Sequence: p.Sequence, MAC: b.cfg.MAC}.MarshalBinary()
+		b.reply(p.Sequence, pkt[4], ack, from)
+	}
+}
+
+// Send a reply, applying the configured fault.
+func (b *Board) reply(seq uint32, cmd byte, buf []byte, to *net.UDPAddr) {
+	fault := Deliver
+	if b.cfg.Fault != nil {
+		fault = b.cfg.Fault(cmd, seq)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	switch fault {
+	case Drop:
+		return
+	case Reorder:
+		b.held = append(b.held, held{buf, to})
+		return
+	case Duplicate:
+		b.conn.WriteToUDP(buf, to)
+	}
+	b.conn.WriteToUDP(buf, to)
+
+	// release anything held back, now out of order
+	for _, h := range b.held {
+		b.conn.WriteToUDP(h.buf, h.to)
+	}
+	b.held = nil
+}
+
+// Rebind the board to a new address after a Set IP packet, keeping the
+// port.  A DHCP request (255.255.255.255) moves it to Config.Dhcpip, an
+// address that cannot be bound on this machine leaves the board where it
+// is.
+func (b *Board) move(ip net.IP) {
+	if ip.Equal(net.IPv4bcast) {
+		ip = b.cfg.Dhcpip
+	}
+	if (ip == nil) || ip.IsUnspecified() {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || ip.Equal(b.ip) {
+		return
+	}
+	port := b.conn.LocalAddr().(*net.UDPAddr).Port
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: ip, Port: port})
+	if err != nil {
+		log.Printf("simhpsdr: cannot move to %v: %v", ip, err)
+		return
+	}
+	old := b.conn
+	b.conn = conn
+	b.ip = ip
+	old.Close()
+	go b.serve(conn)
+}